@@ -14,6 +14,23 @@ var (
 	ErrCodependency    = errors.New("codependency between individuals")
 )
 
+// EvaluationError is returned when the evaluation of an individual fails.
+// See [Evaluator].
+type EvaluationError struct {
+	// Individual is the Genome whose evaluation failed.
+	Individual any
+	// Err is the error returned by Evaluate.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *EvaluationError) Error() string {
+	return "evaluation of individual failed: " + e.Err.Error()
+}
+
+// Unwrap returns the error returned by Evaluate.
+func (e *EvaluationError) Unwrap() error { return e.Err }
+
 // FindCodependecy returns error if inconsistency detected in newIndividual function
 // for use with mu8.Genome genetic algorithm implementations.
 //
@@ -66,19 +66,20 @@ func main() {
 	}
 
 	pop := genetic.NewPopulation(individuals, src, func() *rocket { return baseRocket.Clone() })
-	for i := 0; i < Ngen; i++ {
-		err := pop.Advance(context.Background())
-		if err != nil {
-			panic(err)
-		}
-		err = pop.Selection(mutrate, polygamy)
-		if err != nil {
-			panic(err)
-		}
-		if i%(Ngen/(Nprints-1)) == 0 || i == Ngen-1 {
-			bestFitness := pop.ChampionFitness()
-			fmt.Printf("champHeight:%.3fkm\n", bestFitness)
-		}
+	_, err := pop.Run(context.Background(), genetic.RunOptions{
+		MutationRate:   mutrate,
+		Polygamy:       polygamy,
+		MaxGenerations: Ngen,
+		OnGeneration: func(stats genetic.Stats) bool {
+			i := stats.Generation
+			if i%(Ngen/(Nprints-1)) == 0 || i == Ngen-1 {
+				fmt.Printf("champHeight:%.3fkm\n", pop.ChampionFitness())
+			}
+			return false
+		},
+	})
+	if err != nil {
+		panic(err)
 	}
 	best := pop.Champion()
 	fmt.Println("our champion:", best)
@@ -75,6 +75,28 @@ func (c *ConstrainedFloat) Splice(rng *rand.Rand, g mu8.Gene) {
 	c.gene = c.clamp((c.gene*randi + co.gene*random) / 2)
 }
 
+// Distance returns the absolute difference between the receiver's and g's values
+// divided by the length of the constraint range. Distance implements the
+// [mu8.GeneDistancer] interface. If g is not of type *ConstrainedFloat, Distance panics.
+func (c *ConstrainedFloat) Distance(g mu8.Gene) float64 {
+	co := castGene[*ConstrainedFloat](g)
+	length := c.rangeLength()
+	if length == 0 {
+		return 0
+	}
+	return math.Abs(c.gene-co.gene) / length
+}
+
+// Equal reports whether the receiver and g have the same value. Equal implements the
+// [mu8.GeneHasher] interface. If g is not of type *ConstrainedFloat, Equal panics.
+func (c *ConstrainedFloat) Equal(g mu8.Gene) bool {
+	co := castGene[*ConstrainedFloat](g)
+	return c.gene == co.gene
+}
+
+// Hash returns a hash of the gene's value. Hash implements the [mu8.GeneHasher] interface.
+func (c *ConstrainedFloat) Hash() uint64 { return hashFloat(c.gene) }
+
 func (c *ConstrainedFloat) Format(state fmt.State, verb rune) {
 	var val string
 	prec, okp := state.Precision()
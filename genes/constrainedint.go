@@ -85,6 +85,28 @@ func (c *ConstrainedInt) Splice(rng *rand.Rand, g mu8.Gene) {
 	c.gene = minGene + random
 }
 
+// Distance returns the absolute difference between the receiver's and g's values
+// divided by the length of the constraint range. Distance implements the
+// [mu8.GeneDistancer] interface. If g is not of type *ConstrainedInt, Distance panics.
+func (c *ConstrainedInt) Distance(g mu8.Gene) float64 {
+	co := castGene[*ConstrainedInt](g)
+	diff := c.gene - co.gene
+	if diff < 0 {
+		diff = -diff
+	}
+	return float64(diff) / float64(c.rangeMinus1+1)
+}
+
+// Equal reports whether the receiver and g have the same value. Equal implements the
+// [mu8.GeneHasher] interface. If g is not of type *ConstrainedInt, Equal panics.
+func (c *ConstrainedInt) Equal(g mu8.Gene) bool {
+	co := castGene[*ConstrainedInt](g)
+	return c.gene == co.gene
+}
+
+// Hash returns a hash of the gene's value. Hash implements the [mu8.GeneHasher] interface.
+func (c *ConstrainedInt) Hash() uint64 { return mix(uint64(c.gene)) }
+
 // String returns a string representation of the gene.
 func (c *ConstrainedInt) String() string {
 	return fmt.Sprintf("%d", c.gene)
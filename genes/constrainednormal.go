@@ -54,6 +54,25 @@ func (cn *ConstrainedNormalDistr) Splice(rng *rand.Rand, g mu8.Gene) {
 	cn.clamp()
 }
 
+// Distance returns the absolute difference between the receiver's and g's values
+// divided by the length of the constraint range. It implements the [mu8.GeneDistancer] interface.
+// If g is not of type *ConstrainedNormalDistr, Distance panics.
+func (cn *ConstrainedNormalDistr) Distance(g mu8.Gene) float64 {
+	co := castGene[*ConstrainedNormalDistr](g)
+	length := cn.maxMinus3sd - cn.minPlus3sd + 6*cn.StdDev()
+	if length == 0 {
+		return 0
+	}
+	return math.Abs(cn.gene-co.gene) / length
+}
+
+// Equal reports whether the receiver and g have the same value. It implements the [mu8.GeneHasher] interface.
+// If g is not of type *ConstrainedNormalDistr, Equal panics.
+func (cn *ConstrainedNormalDistr) Equal(g mu8.Gene) bool {
+	co := castGene[*ConstrainedNormalDistr](g)
+	return cn.gene == co.gene
+}
+
 // Copy returns a copy of the gene.
 func (cn *ConstrainedNormalDistr) Copy() *ConstrainedNormalDistr {
 	clone := *cn
@@ -3,6 +3,7 @@ package genes
 import (
 	"errors"
 	"fmt"
+	"math"
 
 	"github.com/soypat/mu8"
 )
@@ -38,8 +39,36 @@ var (
 	_ gene[float64] = (*NormalDistribution)(nil)
 	_ gene[float64] = (*ConstrainedNormalDistr)(nil)
 	_ gene[int]     = (*ConstrainedInt)(nil)
+
+	_ mu8.GeneDistancer = (*ConstrainedFloat)(nil)
+	_ mu8.GeneDistancer = (*NormalDistribution)(nil)
+	_ mu8.GeneDistancer = (*ConstrainedNormalDistr)(nil)
+	_ mu8.GeneDistancer = (*ConstrainedInt)(nil)
+
+	_ mu8.GeneHasher = (*ConstrainedFloat)(nil)
+	_ mu8.GeneHasher = (*NormalDistribution)(nil)
+	_ mu8.GeneHasher = (*ConstrainedNormalDistr)(nil)
+	_ mu8.GeneHasher = (*ConstrainedInt)(nil)
 )
 
+// hashFloat returns a well mixed hash of v. Positive and negative zero have the same hash.
+func hashFloat(v float64) uint64 {
+	if v == 0 {
+		v = 0
+	}
+	return mix(math.Float64bits(v))
+}
+
+// mix is the splitmix64 finalizer, which spreads the bits of x over the hash.
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
 type integer interface {
 	int | int64 | int32 | int16 | int8 | uint | uint64 | uint32 | uint16 | uint8
 }
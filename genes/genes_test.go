@@ -2,7 +2,10 @@ package genes
 
 import (
 	"fmt"
+	"math"
 	"testing"
+
+	"github.com/soypat/mu8"
 )
 
 func TestConstrainedFloatFormat(t *testing.T) {
@@ -13,3 +16,54 @@ func TestConstrainedFloatFormat(t *testing.T) {
 		t.Error("bad format")
 	}
 }
+
+func TestGeneDistance(t *testing.T) {
+	a := NewConstrainedFloat(0.25, 0, 2)
+	b := NewConstrainedFloat(1.25, 0, 2)
+	if d := a.Distance(b); d != 0.5 {
+		t.Errorf("ConstrainedFloat distance: got %g, expected 0.5", d)
+	}
+	if d := a.Distance(a); d != 0 {
+		t.Errorf("ConstrainedFloat distance to itself: got %g", d)
+	}
+	ia := NewConstrainedInt(0, 0, 10)
+	ib := NewConstrainedInt(5, 0, 10)
+	if d := ia.Distance(ib); d != 0.5 {
+		t.Errorf("ConstrainedInt distance: got %g, expected 0.5", d)
+	}
+	na := NewNormalDistribution(0, 2)
+	nb := NewNormalDistribution(4, 2)
+	if d := na.Distance(nb); d != 2 {
+		t.Errorf("NormalDistribution distance: got %g, expected 2", d)
+	}
+	// Degenerate standard deviation must not yield Inf or NaN.
+	nz := &NormalDistribution{gene: 1, stdDevMinus1: -1}
+	if d := nz.Distance(&NormalDistribution{gene: 4, stdDevMinus1: -1}); d != 3 {
+		t.Errorf("NormalDistribution zero standard deviation distance: got %g, expected 3", d)
+	}
+	if d := nz.Distance(nz); d != 0 {
+		t.Errorf("NormalDistribution zero standard deviation self distance: got %g, expected 0", d)
+	}
+}
+
+func TestGeneEqualHash(t *testing.T) {
+	genes := []struct {
+		a, b, c mu8.GeneHasher
+	}{
+		{NewConstrainedFloat(0.25, 0, 2), NewConstrainedFloat(0.25, 0, 2), NewConstrainedFloat(1, 0, 2)},
+		{NewConstrainedInt(3, 0, 10), NewConstrainedInt(3, 0, 10), NewConstrainedInt(4, 0, 10)},
+		{NewNormalDistribution(0, 1), NewNormalDistribution(0, 1), NewNormalDistribution(1, 1)},
+		{NewConstrainedNormalDistr(0.5, 0.1, 0, 1), NewConstrainedNormalDistr(0.5, 0.1, 0, 1), NewConstrainedNormalDistr(0.6, 0.1, 0, 1)},
+	}
+	for _, g := range genes {
+		if !g.a.Equal(g.b) || g.a.Hash() != g.b.Hash() {
+			t.Errorf("%T: equal genes not equal or with different hash", g.a)
+		}
+		if g.a.Equal(g.c) || g.a.Hash() == g.c.Hash() {
+			t.Errorf("%T: different genes equal or with same hash", g.a)
+		}
+	}
+	if NewNormalDistribution(0, 1).Hash() != NewNormalDistribution(math.Copysign(0, -1), 1).Hash() {
+		t.Error("positive and negative zero hashes differ")
+	}
+}
@@ -2,6 +2,7 @@ package genes
 
 import (
 	"fmt"
+	"math"
 	"math/rand"
 
 	"github.com/soypat/mu8"
@@ -45,6 +46,27 @@ func (n *NormalDistribution) Splice(rng *rand.Rand, g mu8.Gene) {
 	n.gene = rng.NormFloat64()*n.StdDev() + (co.gene+n.gene)/2
 }
 
+// Distance returns the absolute difference between the receiver's and g's values
+// measured in standard deviations. If the standard deviation is not positive the
+// absolute difference is returned. It implements the [mu8.GeneDistancer] interface.
+func (n *NormalDistribution) Distance(g mu8.Gene) float64 {
+	co := castGene[*NormalDistribution](g)
+	d := math.Abs(n.gene - co.gene)
+	if sd := n.StdDev(); sd > 0 {
+		d /= sd
+	}
+	return d
+}
+
+// Equal reports whether the receiver and g have the same value. It implements the [mu8.GeneHasher] interface.
+func (n *NormalDistribution) Equal(g mu8.Gene) bool {
+	co := castGene[*NormalDistribution](g)
+	return n.gene == co.gene
+}
+
+// Hash returns a hash of the gene's value. It implements the [mu8.GeneHasher] interface.
+func (n *NormalDistribution) Hash() uint64 { return hashFloat(n.gene) }
+
 // Copy returns a copy of the gene.
 func (n *NormalDistribution) Copy() *NormalDistribution {
 	clone := *n
@@ -0,0 +1,129 @@
+package genetic
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// AdvanceAsync runs the genetic algorithm on all islands asynchronously until ctx is cancelled
+// or an island fails. Unlike Advance, islands do not wait for each other: each island runs
+// continuously on its own goroutine and every Ngen generations sends migrants to its
+// destination islands through buffered channels. Islands receive pending migrants between
+// generations. Migrants sent to an island whose channel is full are discarded so that fast
+// islands are never blocked by slow ones. Migration is configured with [Islands.SetMigration]
+// where Interval counts periods of Ngen generations of the sending island.
+//
+// AdvanceAsync returns nil when stopped by ctx or the error of the first island to fail.
+// Champion and ChampionFitness may be called concurrently to follow progress.
+// Crossover need not be called after AdvanceAsync.
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+//	defer cancel()
+//	go func() {
+//		for range time.Tick(time.Second) {
+//			fmt.Println(isls.ChampionFitness())
+//		}
+//	}()
+//	err := isls.AdvanceAsync(ctx, 0.1, 1, 10)
+func (is *Islands[G]) AdvanceAsync(ctx context.Context, mutationRate float64, polygamy, Ngen int) error {
+	I := len(is.islands)
+	switch {
+	case Ngen <= 0:
+		panic("number of generations between migrations must be greater or equal to 1")
+	case ctx.Err() != nil:
+		return ctx.Err()
+	}
+	start := time.Now()
+	startEvals := is.evaluations()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Each island has an inbox buffered so that all other islands may send a batch of migrants.
+	inbox := make([]chan []migrant[G], I)
+	for i := range inbox {
+		inbox[i] = make(chan []migrant[G], I)
+	}
+	errChan := make(chan error, I)
+	var wg sync.WaitGroup
+	for i := 0; i < I; i++ {
+		i := i // Loop variable escape for closures.
+		wg.Add(1)
+		go func() (err error) {
+			defer func() {
+				a := recover()
+				if a != nil {
+					err = fmt.Errorf("island (population) %d panic: %s", i, a)
+				}
+				if err != nil && ctx.Err() == nil {
+					// Errors caused by cancellation are not island failures.
+					errChan <- err
+				}
+				cancel()
+				wg.Done()
+			}()
+			return is.runAsync(ctx, i, inbox, mutationRate, polygamy, Ngen)
+		}()
+	}
+	wg.Wait()
+
+	is.stats = is.aggregateStats(is.evaluations()-startEvals, time.Since(start))
+	if len(errChan) > 0 {
+		return <-errChan
+	}
+	return nil
+}
+
+// runAsync runs the ith island until ctx is cancelled, exchanging migrants through inbox.
+func (is *Islands[G]) runAsync(ctx context.Context, i int, inbox []chan []migrant[G], mutationRate float64, polygamy, Ngen int) error {
+	isle := &is.islands[i]
+	m := is.migration
+	topology, emigration, immigration := m.topology(), m.emigration(), m.immigration()
+	for epoch := 1; ; epoch++ {
+		for g := 0; g < Ngen; g++ {
+			if ctx.Err() != nil {
+				return nil
+			}
+			err := isle.generation(ctx, mutationRate, polygamy, false)
+			if err != nil {
+				return err
+			}
+			is.publish(i)
+			err = isle.receiveAsync(immigration, inbox[i])
+			if err != nil {
+				return err
+			}
+		}
+		is.updateAttractiveness(i)
+		if epoch%m.interval() != 0 {
+			continue
+		}
+		emigrants, err := isle.emigrants(emigration, m.migrants())
+		if err != nil {
+			return err
+		}
+		for _, j := range topology.Destinations(&isle.rng, i, len(is.islands)) {
+			select {
+			case inbox[j] <- emigrants:
+			default:
+				// Destination island is lagging behind, discard migrants.
+			}
+		}
+	}
+}
+
+// receiveAsync receives all pending migrants in inbox without blocking.
+func (is *island[G]) receiveAsync(policy Immigration, inbox <-chan []migrant[G]) error {
+	for {
+		select {
+		case migrants := <-inbox:
+			err := is.receiveMigrants(policy, migrants)
+			if err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+	}
+}
@@ -0,0 +1,533 @@
+package genetic
+
+import (
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/soypat/mu8"
+)
+
+const checkpointVersion = 1
+
+var (
+	errBadCheckpoint     = errors.New("bad checkpoint data")
+	errUnsavableSource   = errors.New("rand.Source does not implement encoding.BinaryMarshaler and encoding.BinaryUnmarshaler: cannot checkpoint its state")
+	errUnsavableState    = errors.New("mutation controller or constraint handler state was saved but does not implement encoding.BinaryUnmarshaler")
+	errIslandsMismatch   = errors.New("number of islands in checkpoint does not match Islands")
+	errCodecNotSet       = errors.New("Codec Marshal and Unmarshal functions must be set")
+	populationCheckpoint = [4]byte{'m', 'u', '8', 'P'}
+	islandsCheckpoint    = [4]byte{'m', 'u', '8', 'I'}
+)
+
+// Codec encodes and decodes Genomes so that they may be checkpointed with Save and Load.
+type Codec[G mu8.Genome] struct {
+	// Marshal returns the binary encoding of the genome.
+	Marshal func(G) ([]byte, error)
+	// Unmarshal decodes data into dst, a blank-slate genome returned by newIndividual.
+	Unmarshal func(dst G, data []byte) error
+}
+
+// Save writes the state of the Population to w so that it can later be resumed with Load.
+// Saved state includes the individuals, their fitness, the champion, generation and
+// evaluation counters, statistics, the state of the random number generator, the individuals of the
+// HallOfFame and the state of the MutationController and ConstraintHandler if they implement encoding.BinaryMarshaler.
+//
+// The rand.Source passed to NewPopulation must implement encoding.BinaryMarshaler and
+// encoding.BinaryUnmarshaler for its state to be saved, such as [mu8.Source].
+// Configuration set with setter methods, such as the Selector or Goal, is not saved.
+func (pop *Population[G]) Save(w io.Writer, codec Codec[G]) error {
+	if codec.Marshal == nil || codec.Unmarshal == nil {
+		return errCodecNotSet
+	}
+	e := encoder{w: w}
+	e.write(populationCheckpoint[:])
+	e.uint(checkpointVersion)
+	pop.encode(&e, codec)
+	encodeHallOfFame(&e, codec, pop.hallOfFame)
+	return e.err
+}
+
+// Load restores Population state written by Save from r. The Population should have been
+// created with NewPopulation and configured with the same setters as the saved Population so that a
+// resumed run continues identically to an uninterrupted one. Saved HallOfFame individuals replace those of the
+// Population's HallOfFame, if set. On error the Population is left in an undefined state.
+func (pop *Population[G]) Load(r io.Reader, codec Codec[G]) error {
+	if codec.Marshal == nil || codec.Unmarshal == nil {
+		return errCodecNotSet
+	}
+	d := decoder{r: r}
+	err := d.header(populationCheckpoint)
+	if err != nil {
+		return err
+	}
+	pop.decode(&d, codec)
+	decodeHallOfFame(&d, codec, pop.hallOfFame, pop.generator)
+	return d.err
+}
+
+// Save writes the state of all islands to w so that it can later be resumed with Load.
+// Migrants selected by Advance are saved so that a checkpoint may be taken between
+// Advance and Crossover. The HallOfFame shared by islands is saved once. See [Population.Save].
+func (is *Islands[G]) Save(w io.Writer, codec Codec[G]) error {
+	if codec.Marshal == nil || codec.Unmarshal == nil {
+		return errCodecNotSet
+	}
+	e := encoder{w: w}
+	e.write(islandsCheckpoint[:])
+	e.uint(checkpointVersion)
+	e.int(len(is.islands))
+	e.source(is.src)
+	e.stats(is.stats)
+	e.int(is.epoch)
+	for i := range is.islands {
+		isle := &is.islands[i]
+		isle.Population.encode(&e, codec)
+		e.floats(isle.prevFitness)
+		e.float(isle.attr)
+		// Migrants selected by Advance are pending until Crossover.
+		e.int(len(is.mw[i]))
+		for _, m := range is.mw[i] {
+			encodeGenome(&e, codec, m.ind)
+			e.float(m.fitness)
+			e.float(m.violation)
+		}
+	}
+	encodeHallOfFame(&e, codec, is.islands[0].hallOfFame)
+	return e.err
+}
+
+// Load restores Islands state written by Save from r. Islands must have been created
+// with NewIslands with the same number of islands. See [Population.Load].
+func (is *Islands[G]) Load(r io.Reader, codec Codec[G]) error {
+	if codec.Marshal == nil || codec.Unmarshal == nil {
+		return errCodecNotSet
+	}
+	d := decoder{r: r}
+	err := d.header(islandsCheckpoint)
+	if err != nil {
+		return err
+	}
+	if d.int() != len(is.islands) && d.err == nil {
+		return errIslandsMismatch
+	}
+	d.source(is.src)
+	is.stats = d.stats()
+	is.epoch = d.int()
+	for i := range is.islands {
+		isle := &is.islands[i]
+		isle.Population.decode(&d, codec)
+		isle.prevFitness = d.floats()
+		isle.attr = d.float()
+		nmigrants := d.length(8)
+		is.mw[i] = nil
+		for k := 0; k < nmigrants && d.err == nil; k++ {
+			is.mw[i] = append(is.mw[i], migrant[G]{ind: decodeGenome(&d, codec, isle.generator()), fitness: d.float(), violation: d.float()})
+		}
+		is.publish(i)
+	}
+	decodeHallOfFame(&d, codec, is.islands[0].hallOfFame, is.islands[0].generator)
+	return d.err
+}
+
+func (pop *Population[G]) encode(e *encoder, codec Codec[G]) {
+	e.source(pop.src)
+	e.int(len(pop.individuals))
+	for _, ind := range pop.individuals {
+		encodeGenome(e, codec, ind)
+	}
+	e.bool(pop.hasChamp)
+	if pop.hasChamp {
+		encodeGenome(e, codec, pop.champ)
+	}
+	e.float(pop.champFitness)
+	e.floats(pop.fitness)
+	e.floats(pop.weights)
+	e.float(pop.fitnessSum)
+	e.int(pop.gen)
+	e.int(pop.evals)
+	e.bool(pop.evaluated)
+	for _, c := range pop.cached {
+		e.bool(c)
+	}
+	e.floats(pop.cache)
+	// Sort hashes so that output is deterministic.
+	hashes := make([]uint64, 0, len(pop.memo))
+	for h := range pop.memo {
+		hashes = append(hashes, h)
+	}
+	sort.Slice(hashes, func(i, j int) bool { return hashes[i] < hashes[j] })
+	e.bool(pop.memo != nil)
+	e.int(len(hashes))
+	for _, h := range hashes {
+		e.uint(h)
+		e.int(len(pop.memo[h]))
+		for _, m := range pop.memo[h] {
+			encodeGenome(e, codec, m.ind)
+			e.float(m.fitness)
+			e.float(m.violation)
+		}
+	}
+	e.floats(pop.parentFitness)
+	e.float(pop.mutationRate)
+	e.stats(pop.stats)
+	e.state(pop.controller)
+	e.floats(pop.violation)
+	e.floats(pop.violationCache)
+	e.bool(pop.hasInfeasibleChamp)
+	if pop.hasInfeasibleChamp {
+		encodeGenome(e, codec, pop.infeasibleChamp)
+	}
+	e.float(pop.infeasibleFitness)
+	e.float(pop.infeasibleViolation)
+	e.state(pop.constraints)
+	e.bool(pop.rivals != nil)
+	for _, r := range pop.rivals {
+		e.bool(r.ok)
+		if r.ok {
+			encodeGenome(e, codec, r.ind)
+			e.float(r.fitness)
+			e.float(r.violation)
+		}
+	}
+}
+
+func (pop *Population[G]) decode(d *decoder, codec Codec[G]) {
+	d.source(pop.src)
+	n := d.length(8)
+	individuals := make([]G, 0, n)
+	for i := 0; i < n && d.err == nil; i++ {
+		individuals = append(individuals, decodeGenome(d, codec, pop.generator()))
+	}
+	if d.err != nil {
+		return
+	}
+	pop.individuals = individuals
+	pop.hasChamp = d.bool()
+	pop.champ = pop.generator()
+	if pop.hasChamp {
+		pop.champ = decodeGenome(d, codec, pop.champ)
+	}
+	pop.champFitness = d.float()
+	pop.fitness = d.floats()
+	pop.weights = d.floats()
+	pop.fitnessSum = d.float()
+	pop.gen = d.int()
+	pop.evals = d.int()
+	pop.evaluated = d.bool()
+	pop.cached = make([]bool, n)
+	for i := range pop.cached {
+		pop.cached[i] = d.bool()
+	}
+	pop.cache = d.floats()
+	hasMemo := d.bool()
+	nmemo := d.length(16)
+	pop.memo = nil
+	if hasMemo {
+		pop.memo = make(map[uint64][]memoized[G])
+	}
+	for i := 0; i < nmemo && d.err == nil; i++ {
+		h := d.uint()
+		ncollisions := d.length(16)
+		for k := 0; k < ncollisions && d.err == nil; k++ {
+			pop.memo[h] = append(pop.memo[h], memoized[G]{ind: decodeGenome(d, codec, pop.generator()), fitness: d.float(), violation: d.float()})
+		}
+	}
+	pop.parentFitness = d.floats()
+	pop.mutationRate = d.float()
+	pop.stats = d.stats()
+	d.state(pop.controller)
+	violation := d.floats()
+	violationCache := d.floats()
+	pop.violation, pop.violationCache = nil, nil
+	if len(violation) > 0 {
+		pop.violation, pop.violationCache = violation, violationCache
+	}
+	pop.hasInfeasibleChamp = d.bool()
+	pop.infeasibleChamp = *new(G)
+	if pop.hasInfeasibleChamp {
+		pop.infeasibleChamp = decodeGenome(d, codec, pop.generator())
+	}
+	pop.infeasibleFitness = d.float()
+	pop.infeasibleViolation = d.float()
+	d.state(pop.constraints)
+	pop.rivals = nil
+	if d.bool() {
+		pop.rivals = make([]rival[G], n)
+		for i := range pop.rivals {
+			if d.err == nil && d.bool() {
+				pop.rivals[i] = rival[G]{ind: decodeGenome(d, codec, pop.generator()), fitness: d.float(), violation: d.float(), ok: true}
+			}
+		}
+	}
+	if d.err == nil && (len(pop.fitness) != n || len(pop.weights) != n || len(pop.cache) != n || len(pop.parentFitness) != n ||
+		(pop.violation != nil && (len(pop.violation) != n || len(pop.violationCache) != n))) {
+		d.err = errBadCheckpoint
+	}
+	pop.dubious, pop.dubiousIndividual = 0, *new(G)
+}
+
+// encodeHallOfFame writes the individuals of h, which may be nil.
+func encodeHallOfFame[G mu8.Genome](e *encoder, codec Codec[G], h *HallOfFame[G]) {
+	e.bool(h != nil)
+	if h == nil {
+		return
+	}
+	entries := h.Entries()
+	e.int(len(entries))
+	for _, entry := range entries {
+		encodeGenome(e, codec, entry.Individual)
+		e.float(entry.Fitness)
+		e.int(entry.Generation)
+	}
+}
+
+// decodeHallOfFame restores the individuals saved by encodeHallOfFame into h.
+// Saved individuals are discarded if h is nil.
+func decodeHallOfFame[G mu8.Genome](d *decoder, codec Codec[G], h *HallOfFame[G], generator func() G) {
+	if !d.bool() {
+		return
+	}
+	n := d.length(24)
+	entries := make([]HallOfFameEntry[G], 0, n)
+	for i := 0; i < n && d.err == nil; i++ {
+		entries = append(entries, HallOfFameEntry[G]{Individual: decodeGenome(d, codec, generator()), Fitness: d.float(), Generation: d.int()})
+	}
+	if d.err != nil || h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(entries) > h.size {
+		entries = entries[:h.size]
+	}
+	h.entries = entries
+}
+
+// encoder writes checkpoint data in little endian byte order. Once an error
+// is encountered subsequent writes are no-ops.
+type encoder struct {
+	w   io.Writer
+	buf [8]byte
+	err error
+}
+
+func (e *encoder) write(b []byte) {
+	if e.err == nil {
+		_, e.err = e.w.Write(b)
+	}
+}
+
+func (e *encoder) uint(v uint64) {
+	binary.LittleEndian.PutUint64(e.buf[:], v)
+	e.write(e.buf[:])
+}
+
+func (e *encoder) int(v int)                { e.uint(uint64(v)) }
+func (e *encoder) float(v float64)          { e.uint(math.Float64bits(v)) }
+func (e *encoder) duration(v time.Duration) { e.uint(uint64(v)) }
+
+func (e *encoder) bool(v bool) {
+	if v {
+		e.write([]byte{1})
+	} else {
+		e.write([]byte{0})
+	}
+}
+
+// bytes writes a length-prefixed blob.
+func (e *encoder) bytes(b []byte) {
+	e.int(len(b))
+	e.write(b)
+}
+
+func (e *encoder) floats(s []float64) {
+	e.int(len(s))
+	for _, v := range s {
+		e.float(v)
+	}
+}
+
+func (e *encoder) stats(s Stats) {
+	e.int(s.Generation)
+	for _, v := range [...]float64{s.Min, s.Max, s.Mean, s.Median, s.StdDev} {
+		e.float(v)
+	}
+	e.int(s.Evaluations)
+	e.duration(s.WallTime)
+	e.float(s.MutationRate)
+	e.float(s.SuccessRate)
+}
+
+// state writes the state of v if it implements encoding.BinaryMarshaler,
+// such as a stateful MutationController or ConstraintHandler.
+func (e *encoder) state(v any) {
+	var state []byte
+	if m, ok := v.(encoding.BinaryMarshaler); ok && e.err == nil {
+		state, e.err = m.MarshalBinary()
+	}
+	e.bytes(state)
+}
+
+func (e *encoder) source(src rand.Source) {
+	m, ok := src.(encoding.BinaryMarshaler)
+	if !ok {
+		if e.err == nil {
+			e.err = errUnsavableSource
+		}
+		return
+	}
+	if _, ok := src.(encoding.BinaryUnmarshaler); !ok && e.err == nil {
+		e.err = errUnsavableSource
+		return
+	}
+	var state []byte
+	if e.err == nil {
+		state, e.err = m.MarshalBinary()
+	}
+	e.bytes(state)
+}
+
+func encodeGenome[G mu8.Genome](e *encoder, codec Codec[G], g G) {
+	if e.err != nil {
+		return
+	}
+	var data []byte
+	data, e.err = codec.Marshal(g)
+	e.bytes(data)
+}
+
+// maxBlob limits the size of blobs read from a checkpoint so that
+// corrupted data does not result in huge allocations.
+const maxBlob = 1 << 30
+
+// decoder reads data written by encoder. Once an error is encountered
+// subsequent reads return zero values.
+type decoder struct {
+	r   io.Reader
+	buf [8]byte
+	err error
+}
+
+// header reads and checks the checkpoint magic number and version.
+func (d *decoder) header(magic [4]byte) error {
+	var got [4]byte
+	d.read(got[:])
+	version := d.uint()
+	switch {
+	case d.err != nil:
+		return d.err
+	case got != magic:
+		return fmt.Errorf("%w: not a %s checkpoint", errBadCheckpoint, magic[:])
+	case version != checkpointVersion:
+		return fmt.Errorf("%w: unsupported version %d", errBadCheckpoint, version)
+	}
+	return nil
+}
+
+func (d *decoder) read(b []byte) {
+	if d.err == nil {
+		_, d.err = io.ReadFull(d.r, b)
+		if d.err == io.EOF {
+			d.err = io.ErrUnexpectedEOF
+		}
+	}
+}
+
+func (d *decoder) uint() uint64 {
+	d.read(d.buf[:])
+	if d.err != nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint64(d.buf[:])
+}
+
+func (d *decoder) int() int                { return int(d.uint()) }
+func (d *decoder) float() float64          { return math.Float64frombits(d.uint()) }
+func (d *decoder) duration() time.Duration { return time.Duration(d.uint()) }
+
+func (d *decoder) bool() bool {
+	var b [1]byte
+	d.read(b[:])
+	return b[0] != 0
+}
+
+// length reads a length prefix and checks it is within bounds.
+func (d *decoder) length(size int) int {
+	n := d.uint()
+	if d.err == nil && n > uint64(maxBlob/size) {
+		d.err = fmt.Errorf("%w: length %d too large", errBadCheckpoint, n)
+	}
+	if d.err != nil {
+		return 0
+	}
+	return int(n)
+}
+
+func (d *decoder) bytes() []byte {
+	b := make([]byte, d.length(1))
+	d.read(b)
+	return b
+}
+
+func (d *decoder) floats() []float64 {
+	s := make([]float64, d.length(8))
+	for i := range s {
+		s[i] = d.float()
+	}
+	return s
+}
+
+func (d *decoder) stats() (s Stats) {
+	s.Generation = d.int()
+	for _, v := range [...]*float64{&s.Min, &s.Max, &s.Mean, &s.Median, &s.StdDev} {
+		*v = d.float()
+	}
+	s.Evaluations = d.int()
+	s.WallTime = d.duration()
+	s.MutationRate = d.float()
+	s.SuccessRate = d.float()
+	return s
+}
+
+// state restores the state of v saved by encoder.state.
+func (d *decoder) state(v any) {
+	state := d.bytes()
+	if len(state) == 0 || d.err != nil {
+		return
+	}
+	u, ok := v.(encoding.BinaryUnmarshaler)
+	if !ok {
+		d.err = errUnsavableState
+		return
+	}
+	d.err = u.UnmarshalBinary(state)
+}
+
+// source restores the state of src.
+func (d *decoder) source(src rand.Source) {
+	state := d.bytes()
+	if d.err != nil {
+		return
+	}
+	u, ok := src.(encoding.BinaryUnmarshaler)
+	if !ok {
+		d.err = errUnsavableSource
+		return
+	}
+	d.err = u.UnmarshalBinary(state)
+}
+
+func decodeGenome[G mu8.Genome](d *decoder, codec Codec[G], dst G) G {
+	data := d.bytes()
+	if d.err == nil {
+		d.err = codec.Unmarshal(dst, data)
+	}
+	return dst
+}
@@ -0,0 +1,180 @@
+package genetic
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/soypat/mu8"
+)
+
+func TestCheckpointPopulation(t *testing.T) {
+	const (
+		Nindividuals = 20
+		genomelen    = 4
+		Ngen         = 5
+	)
+	ctx := context.Background()
+	newPop := func(seed int64) Population[*cfgenome] {
+		pop := newTestPopulation(mu8.NewSource(seed), Nindividuals, genomelen)
+		pop.SetMutationController(&OneFifthRule{})
+		pop.SetHallOfFame(NewHallOfFame[*cfgenome](5))
+		return pop
+	}
+	run := func(pop *Population[*cfgenome], generations int) (champs []float64) {
+		for gen := 0; gen < generations; gen++ {
+			err := pop.Advance(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			champs = append(champs, pop.ChampionFitness())
+			err = pop.Selection(0.2, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		return champs
+	}
+	pop := newPop(1)
+	run(&pop, Ngen)
+	var buf bytes.Buffer
+	err := pop.Save(&buf, cfcodec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := run(&pop, Ngen)
+
+	resumed := newPop(2)
+	err = resumed.Load(&buf, cfcodec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := run(&resumed, Ngen)
+	for i := range expect {
+		if got[i] != expect[i] {
+			t.Fatalf("resumed run diverged at generation %d: %g != %g", i, got[i], expect[i])
+		}
+	}
+	if resumed.gen != pop.gen || resumed.Evaluations() != pop.Evaluations() {
+		t.Error("generation or evaluation counters not restored")
+	}
+	if hallOfFameFitness(resumed.hallOfFame) != hallOfFameFitness(pop.hallOfFame) {
+		t.Error("hall of fame not restored")
+	}
+	for i, ind := range resumed.Individuals() {
+		if ind.Simulate(ctx) != pop.Individuals()[i].Simulate(ctx) {
+			t.Fatalf("individual %d differs after resumed run", i)
+		}
+	}
+
+	// Sources that can't be saved result in an error.
+	unsavable := newTestPopulation(rand.NewSource(1), Nindividuals, genomelen)
+	err = unsavable.Save(&buf, cfcodec)
+	if !errors.Is(err, errUnsavableSource) {
+		t.Errorf("expected errUnsavableSource, got %v", err)
+	}
+}
+
+func TestCheckpointIslands(t *testing.T) {
+	const (
+		Nislands     = 3
+		Nindividuals = 30
+		genomelen    = 4
+		Nepochs      = 3
+	)
+	ctx := context.Background()
+	advance := func(is *Islands[*cfgenome]) {
+		err := is.Advance(ctx, 0.2, 1, 2, Nislands)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	crossover := func(is *Islands[*cfgenome]) float64 {
+		err := is.Crossover()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return is.ChampionFitness()
+	}
+	// Checkpoints may be taken after Crossover or between Advance and Crossover.
+	for _, betweenAdvanceAndCrossover := range []bool{false, true} {
+		run := func(is *Islands[*cfgenome], epochs int) (champs []float64) {
+			if betweenAdvanceAndCrossover {
+				champs = append(champs, crossover(is))
+			}
+			for i := 0; i < epochs; i++ {
+				advance(is)
+				champs = append(champs, crossover(is))
+			}
+			return champs
+		}
+		isls := newTestIslands(mu8.NewSource(1), Nislands, Nindividuals, genomelen)
+		isls.SetHallOfFame(NewHallOfFame[*cfgenome](5))
+		for i := 0; i < Nepochs; i++ {
+			advance(&isls)
+			if i < Nepochs-1 || !betweenAdvanceAndCrossover {
+				crossover(&isls)
+			}
+		}
+		var buf bytes.Buffer
+		err := isls.Save(&buf, cfcodec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expect := run(&isls, Nepochs)
+		resumed := newTestIslands(mu8.NewSource(2), Nislands, Nindividuals, genomelen)
+		resumed.SetHallOfFame(NewHallOfFame[*cfgenome](5))
+		err = resumed.Load(&buf, cfcodec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := run(&resumed, Nepochs)
+		for i := range expect {
+			if got[i] != expect[i] {
+				t.Fatalf("resumed run (between Advance and Crossover: %v) diverged at epoch %d: %g != %g",
+					betweenAdvanceAndCrossover, i, got[i], expect[i])
+			}
+		}
+		for i := range isls.islands {
+			if fmt.Sprint(isls.islands[i].fitness) != fmt.Sprint(resumed.islands[i].fitness) {
+				t.Fatalf("resumed island %d diverged (between Advance and Crossover: %v)", i, betweenAdvanceAndCrossover)
+			}
+		}
+		if hallOfFameFitness(resumed.islands[0].hallOfFame) != hallOfFameFitness(isls.islands[0].hallOfFame) {
+			t.Errorf("hall of fame not restored (between Advance and Crossover: %v)", betweenAdvanceAndCrossover)
+		}
+	}
+}
+
+// hallOfFameFitness returns the fitness and generation of the individuals of h formatted for comparison.
+func hallOfFameFitness[G mu8.Genome](h *HallOfFame[G]) string {
+	var s string
+	for _, entry := range h.Entries() {
+		s += fmt.Sprintf("%g@%d ", entry.Fitness, entry.Generation)
+	}
+	return s
+}
+
+var cfcodec = Codec[*cfgenome]{
+	Marshal: func(g *cfgenome) ([]byte, error) {
+		b := make([]byte, 8*len(g.genoma))
+		for i := range g.genoma {
+			binary.LittleEndian.PutUint64(b[8*i:], math.Float64bits(g.genoma[i].Value()))
+		}
+		return b, nil
+	},
+	Unmarshal: func(dst *cfgenome, data []byte) error {
+		if len(data) != 8*len(dst.genoma) {
+			return errors.New("genome length mismatch")
+		}
+		for i := range dst.genoma {
+			dst.genoma[i].SetValue(math.Float64frombits(binary.LittleEndian.Uint64(data[8*i:])))
+		}
+		return nil
+	},
+}
@@ -0,0 +1,313 @@
+package genetic
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/soypat/mu8"
+)
+
+var errInvalidViolation = fmt.Errorf("%w: constraint violation must be non-negative and finite. See pop.DubiousIndividual to recover problematic Genome information", mu8.ErrInvalidFitness)
+
+// ConstraintHandler combines the fitness and constraint violation of individuals of
+// Populations whose Genomes implement [mu8.Constrained]. The adjusted fitness is then passed
+// to the Population's FitnessTransform to obtain selection weights.
+type ConstraintHandler interface {
+	// Handle stores the adjusted fitness of each individual in dst. As with
+	// FitnessTransform, higher values of fitness are better regardless of the Goal. violation holds
+	// the non-negative constraint violation of each individual, zero for feasible individuals.
+	// Adjusted fitness may be negative but must be finite. dst and fitness may be the same slice.
+	Handle(rng *rand.Rand, dst, fitness, violation []float64)
+}
+
+// adaptiveHandler is implemented by ConstraintHandlers whose state is adapted
+// once per generation, before Handle is called.
+type adaptiveHandler interface {
+	adapt(violation []float64)
+}
+
+// Compile-time checks of interface implementation.
+var (
+	_ adaptiveHandler   = (*AdaptivePenalty)(nil)
+	_ ConstraintHandler = FeasibilityRules{}
+	_ ConstraintHandler = (*AdaptivePenalty)(nil)
+	_ ConstraintHandler = StochasticRanking{}
+)
+
+// FeasibilityRules implements Deb's feasibility rules, which require no parameters:
+//  1. A feasible individual is preferred over an infeasible one.
+//  2. Among feasible individuals the fitter is preferred.
+//  3. Among infeasible individuals the one with the lower violation is preferred.
+//
+// Infeasible individuals are assigned the fitness of the least fit feasible individual minus
+// their violation. It is the default ConstraintHandler.
+type FeasibilityRules struct{}
+
+// Handle implements the [ConstraintHandler] interface.
+func (FeasibilityRules) Handle(_ *rand.Rand, dst, fitness, violation []float64) {
+	worst := math.Inf(1)
+	for i, f := range fitness {
+		if violation[i] == 0 {
+			worst = math.Min(worst, f)
+		}
+	}
+	if math.IsInf(worst, 1) {
+		// No feasible individuals: only the violation matters.
+		worst = 0
+	}
+	for i, f := range fitness {
+		if violation[i] == 0 {
+			dst[i] = f
+		} else {
+			dst[i] = worst - violation[i]
+		}
+	}
+}
+
+// AdaptivePenalty subtracts the violation multiplied by a penalty coefficient from the fitness
+// of each individual. The coefficient is adapted once per generation, at the end of Advance or of a
+// SteadyState step, so that the fraction of feasible individuals approaches Target: it is increased
+// when there are too few feasible individuals and decreased otherwise. Recomputing selection weights
+// between generations, such as when islands receive migrants, does not adapt the coefficient.
+// Since AdaptivePenalty is stateful it should not be shared between Populations.
+type AdaptivePenalty struct {
+	// Initial is the starting penalty coefficient. Zero value is treated as 1.
+	Initial float64
+	// Target is the desired fraction of feasible individuals in range (0, 1). Zero value is treated as 0.5.
+	Target float64
+	// Factor by which the coefficient is multiplied (or divided) each generation. Must be in range (0, 1).
+	// Zero value is treated as 0.8.
+	Factor float64
+	// coefficient is the current penalty coefficient. Zero before first call to Handle.
+	coefficient float64
+}
+
+// Coefficient returns the current penalty coefficient.
+func (ap *AdaptivePenalty) Coefficient() float64 {
+	if ap.coefficient == 0 {
+		return ap.initial()
+	}
+	return ap.coefficient
+}
+
+// MarshalBinary saves the state of the penalty so that it can be checkpointed.
+func (ap *AdaptivePenalty) MarshalBinary() ([]byte, error) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], math.Float64bits(ap.coefficient))
+	return b[:], nil
+}
+
+// UnmarshalBinary restores the state saved by MarshalBinary.
+func (ap *AdaptivePenalty) UnmarshalBinary(data []byte) error {
+	if len(data) != 8 {
+		return errBadCheckpoint
+	}
+	ap.coefficient = math.Float64frombits(binary.LittleEndian.Uint64(data))
+	return nil
+}
+
+// Handle implements the [ConstraintHandler] interface.
+func (ap *AdaptivePenalty) Handle(_ *rand.Rand, dst, fitness, violation []float64) {
+	coef := ap.Coefficient()
+	for i, f := range fitness {
+		dst[i] = f - coef*violation[i]
+	}
+}
+
+// adapt implements the adaptiveHandler interface.
+func (ap *AdaptivePenalty) adapt(violation []float64) {
+	target := ap.Target
+	if target == 0 {
+		target = 0.5
+	} else if target <= 0 || target >= 1 {
+		panic("adaptive penalty target must be in range (0, 1)")
+	}
+	factor := ap.Factor
+	if factor == 0 {
+		factor = 0.8
+	} else if factor <= 0 || factor >= 1 {
+		panic("adaptive penalty factor must be in range (0, 1)")
+	}
+	feasible := 0
+	for _, v := range violation {
+		if v == 0 {
+			feasible++
+		}
+	}
+	coef := ap.Coefficient()
+	if float64(feasible)/float64(len(violation)) < target {
+		coef /= factor
+	} else {
+		coef *= factor
+	}
+	ap.coefficient = coef
+}
+
+func (ap *AdaptivePenalty) initial() float64 {
+	if ap.Initial == 0 {
+		return 1
+	}
+	return ap.Initial
+}
+
+// StochasticRanking implements Runarsson and Yao's stochastic ranking. Individuals are
+// ranked by a stochastic bubble sort in which adjacent individuals are compared by fitness
+// if both are feasible or with probability Pf, and by violation otherwise. The adjusted
+// fitness of an individual is its rank, the best individual having the highest.
+type StochasticRanking struct {
+	// Pf is the probability of comparing infeasible individuals by fitness
+	// in range [0, 0.5). Zero value is treated as 0.45.
+	Pf float64
+	// Sweeps is the maximum number of bubble sort sweeps. Zero value is treated as the number of individuals.
+	Sweeps int
+}
+
+// Handle implements the [ConstraintHandler] interface.
+func (sr StochasticRanking) Handle(rng *rand.Rand, dst, fitness, violation []float64) {
+	pf := sr.Pf
+	if pf == 0 {
+		pf = 0.45
+	} else if pf < 0 || pf >= 0.5 {
+		panic("stochastic ranking probability must be in range [0, 0.5)")
+	}
+	sweeps := sr.Sweeps
+	if sweeps <= 0 {
+		sweeps = len(fitness)
+	}
+	ranked := make([]int, len(fitness))
+	for i := range ranked {
+		ranked[i] = i
+	}
+	for sweep := 0; sweep < sweeps; sweep++ {
+		swapped := false
+		for k := 0; k < len(ranked)-1; k++ {
+			a, b := ranked[k], ranked[k+1]
+			var swap bool
+			if (violation[a] == 0 && violation[b] == 0) || rng.Float64() < pf {
+				swap = fitness[b] > fitness[a]
+			} else {
+				swap = violation[b] < violation[a]
+			}
+			if swap {
+				ranked[k], ranked[k+1] = b, a
+				swapped = true
+			}
+		}
+		if !swapped {
+			break
+		}
+	}
+	for k, i := range ranked {
+		dst[i] = float64(len(ranked) - k)
+	}
+}
+
+// SetConstraintHandler sets how constraint violations are accounted for during selection. It only has
+// effect if the Population's Genomes implement [mu8.Constrained]. Passing nil restores
+// the default [FeasibilityRules].
+//
+// Regardless of the ConstraintHandler the Champion and elite are chosen using feasibility rules,
+// so the Champion is always feasible. The best infeasible individual is reported separately
+// by InfeasibleChampion.
+//
+//	pop.SetConstraintHandler(&genetic.AdaptivePenalty{Initial: 10})
+func (pop *Population[G]) SetConstraintHandler(h ConstraintHandler) { pop.constraints = h }
+
+// InfeasibleChampion returns the infeasible individual with the lowest constraint violation found
+// over all calls to Advance, along with its fitness and violation. Ties in violation are resolved
+// by fitness. ok is false if no infeasible individual has been found.
+func (pop *Population[G]) InfeasibleChampion() (champ G, fitness, violation float64, ok bool) {
+	return pop.infeasibleChamp, pop.infeasibleFitness, pop.infeasibleViolation, pop.hasInfeasibleChamp
+}
+
+func (pop *Population[G]) constraintHandler() ConstraintHandler {
+	if pop.constraints == nil {
+		return FeasibilityRules{}
+	}
+	return pop.constraints
+}
+
+// feasible reports whether the ith individual satisfies its constraints.
+// Individuals of unconstrained Populations are always feasible.
+func (pop *Population[G]) feasible(i int) bool {
+	return pop.violation == nil || pop.violation[i] == 0
+}
+
+// violationOf returns the constraint violation of the ith individual.
+func (pop *Population[G]) violationOf(i int) float64 {
+	if pop.violation == nil {
+		return 0
+	}
+	return pop.violation[i]
+}
+
+// validViolation reports whether the violation of the ith individual can be used by the genetic algorithm.
+func (pop *Population[G]) validViolation(i int) bool {
+	return pop.violation == nil || validViolation(pop.violation[i])
+}
+
+func validViolation(v float64) bool {
+	return v >= 0 && !math.IsInf(v, 0)
+}
+
+// preferred compares two individuals using feasibility rules and reports
+// whether individual a is preferred over individual b.
+func (pop *Population[G]) preferred(fitnessA, violationA, fitnessB, violationB float64) bool {
+	if violationA != violationB {
+		return violationA < violationB
+	}
+	return pop.goal.better(fitnessA, fitnessB)
+}
+
+// feasibilityRanking returns the indices of individuals sorted from most to
+// least preferred according to feasibility rules.
+func (pop *Population[G]) feasibilityRanking(fitness []float64) []int {
+	idx := make([]int, len(fitness))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool {
+		i, j := idx[a], idx[b]
+		return pop.preferred(fitness[i], pop.violation[i], fitness[j], pop.violation[j])
+	})
+	return idx
+}
+
+// updateChampions updates the champion and infeasible champion given
+// the ranking of individuals found during last call to Advance or SteadyState.
+func (pop *Population[G]) updateChampions(ranked []int) error {
+	if pop.feasible(ranked[0]) {
+		err := pop.updateChampion(ranked[0])
+		if err != nil {
+			return err
+		}
+	}
+	if pop.violation == nil {
+		return nil
+	}
+	// Infeasible individuals rank last, the one with least violation first.
+	best := -1
+	for _, i := range ranked {
+		if !pop.feasible(i) {
+			best = i
+			break
+		}
+	}
+	if best < 0 || (pop.hasInfeasibleChamp &&
+		pop.preferred(pop.infeasibleFitness, pop.infeasibleViolation, pop.fitness[best], pop.violation[best])) {
+		return nil
+	}
+	newChamp := pop.generator()
+	err := mu8.Clone(newChamp, pop.individuals[best])
+	if err != nil {
+		return err
+	}
+	pop.infeasibleChamp = newChamp
+	pop.infeasibleFitness = pop.fitness[best]
+	pop.infeasibleViolation = pop.violation[best]
+	pop.hasInfeasibleChamp = true
+	return nil
+}
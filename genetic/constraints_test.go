@@ -0,0 +1,213 @@
+package genetic
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/soypat/mu8"
+)
+
+// budgetGenome maximizes the sum of its genes subject to the sum not exceeding budget.
+type budgetGenome struct {
+	*cfgenome
+	violation float64
+}
+
+const budget = 2
+
+func (g *budgetGenome) Simulate(ctx context.Context) float64 {
+	sum := 0.0
+	for i := range g.genoma {
+		sum += g.genoma[i].Value()
+	}
+	g.violation = math.Max(0, sum-budget)
+	return g.cfgenome.Simulate(ctx)
+}
+
+func (g *budgetGenome) Violation() float64 { return g.violation }
+
+func newBudgetPopulation(src rand.Source, Nindividuals, genomelen int) Population[*budgetGenome] {
+	individuals := make([]*budgetGenome, Nindividuals)
+	for i := range individuals {
+		genome := &budgetGenome{cfgenome: newGenome(genomelen)}
+		mu8.Mutate(genome, src, 1)
+		individuals[i] = genome
+	}
+	return NewPopulation(individuals, src, func() *budgetGenome {
+		return &budgetGenome{cfgenome: newGenome(genomelen)}
+	})
+}
+
+func TestConstraintHandlers(t *testing.T) {
+	fitness := []float64{5, 1, 3, 10, 8}
+	violation := []float64{0, 0, 2, 1, 0}
+	rng := rand.New(rand.NewSource(1))
+	dst := make([]float64, len(fitness))
+	FeasibilityRules{}.Handle(rng, dst, fitness, violation)
+	// Expected order: 4, 0, 1 (feasible by fitness), 3, 2 (infeasible by violation).
+	for _, pair := range [][2]int{{4, 0}, {0, 1}, {1, 3}, {3, 2}} {
+		if dst[pair[0]] <= dst[pair[1]] {
+			t.Fatalf("feasibility rules: individual %d should be preferred over %d: %v", pair[0], pair[1], dst)
+		}
+	}
+	handlers := []ConstraintHandler{FeasibilityRules{}, &AdaptivePenalty{}, StochasticRanking{}}
+	for _, h := range handlers {
+		h.Handle(rng, dst, fitness, violation)
+		for i := range dst {
+			if math.IsInf(dst[i], 0) || math.IsNaN(dst[i]) {
+				t.Fatalf("%T: bad adjusted fitness %v", h, dst)
+			}
+		}
+		// The best feasible individual beats the individual with the most violation.
+		if dst[4] <= dst[2] {
+			t.Errorf("%T: infeasible individual preferred over best feasible: %v", h, dst)
+		}
+	}
+}
+
+func TestPopulationConstraints(t *testing.T) {
+	const maxFeasible = budget / 4.0 / 3
+	ctx := context.Background()
+	handlers := []ConstraintHandler{nil, &AdaptivePenalty{}, StochasticRanking{}}
+	for _, h := range handlers {
+		pop := newBudgetPopulation(rand.NewSource(1), 30, 4)
+		pop.SetConstraintHandler(h)
+		for gen := 0; gen < 30; gen++ {
+			err := pop.Advance(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = pop.Selection(0.2, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		champ := pop.Champion()
+		fitness := champ.Simulate(ctx)
+		if champ.Violation() != 0 || fitness != pop.ChampionFitness() {
+			t.Fatalf("%T: champion infeasible or out of sync: violation %g", h, champ.Violation())
+		}
+		if fitness > maxFeasible+1e-12 || fitness < 0.9*maxFeasible {
+			t.Errorf("%T: expected champion fitness close to %g, got %g", h, maxFeasible, fitness)
+		}
+		_, infeasibleFitness, violation, ok := pop.InfeasibleChampion()
+		if !ok || violation <= 0 {
+			t.Errorf("%T: expected infeasible champion with positive violation, got %g (ok=%v)", h, violation, ok)
+		} else if infeasibleFitness <= maxFeasible {
+			t.Errorf("%T: infeasible champion fitness %g does not exceed feasible bound", h, infeasibleFitness)
+		}
+	}
+}
+
+func TestAdaptivePenaltyOncePerGeneration(t *testing.T) {
+	ctx := context.Background()
+	ap := &AdaptivePenalty{}
+	pop := newBudgetPopulation(rand.NewSource(1), 30, 4)
+	pop.SetConstraintHandler(ap)
+	for gen := 0; gen < 5; gen++ {
+		coef := ap.Coefficient()
+		err := pop.Advance(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		adapted := ap.Coefficient()
+		if adapted != coef/0.8 && adapted != coef*0.8 {
+			t.Fatalf("generation %d: expected coefficient %g adapted once, got %g", gen, coef, adapted)
+		}
+		// Weights recomputed between generations, i.e: after receiving migrants.
+		_, err = pop.computeWeights(false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ap.Coefficient() != adapted {
+			t.Fatalf("generation %d: coefficient adapted outside of Advance", gen)
+		}
+		err = pop.Selection(0.2, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFitnessCacheConstraints(t *testing.T) {
+	const Nindividuals = 30
+	ctx := context.Background()
+	src := mu8.NewSource(1)
+	newIndividual := func() *hashedBudgetGenome { return &hashedBudgetGenome{&budgetGenome{cfgenome: newGenome(4)}} }
+	individuals := make([]*hashedBudgetGenome, Nindividuals)
+	for i := range individuals {
+		individuals[i] = newIndividual()
+		mu8.Mutate(individuals[i], src, 1)
+	}
+	pop := NewPopulation(individuals, src, newIndividual)
+	pop.SetFitnessCache(true)
+	err := pop.Advance(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Memoized violations are checkpointed.
+	var buf bytes.Buffer
+	codec := Codec[*hashedBudgetGenome]{
+		Marshal:   func(g *hashedBudgetGenome) ([]byte, error) { return cfcodec.Marshal(g.cfgenome) },
+		Unmarshal: func(dst *hashedBudgetGenome, data []byte) error { return cfcodec.Unmarshal(dst.cfgenome, data) },
+	}
+	err = pop.Save(&buf, codec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = pop.Load(&buf, codec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	infeasible := argsort(pop.violation)[0] // Most infeasible individual.
+	violation := pop.violation[infeasible]
+	if violation == 0 {
+		t.Fatal("expected an infeasible individual")
+	}
+	memoized := newIndividual()
+	err = mu8.Clone(memoized, pop.Individuals()[infeasible])
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = pop.Selection(0.2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Children identical to an individual of the last generation are memoized by hash.
+	// The first half, which includes the cached elite, is left untouched.
+	for i := Nindividuals / 2; i < Nindividuals; i++ {
+		err = mu8.Clone(pop.Individuals()[i], memoized)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	evals := pop.Evaluations()
+	err = pop.Advance(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := Nindividuals / 2; i < Nindividuals; i++ {
+		if pop.violation[i] != violation {
+			t.Fatalf("memoized individual %d has violation %g, want %g", i, pop.violation[i], violation)
+		}
+	}
+	if pop.Evaluations()-evals != Nindividuals/2-1 {
+		t.Errorf("expected memoized individuals not to be simulated, got %d evaluations", pop.Evaluations()-evals)
+	}
+}
+
+// hashedBudgetGenome is a budgetGenome whose fitness may be memoized by hash.
+type hashedBudgetGenome struct {
+	*budgetGenome
+}
+
+func (g *hashedBudgetGenome) Hash() uint64 {
+	h, err := mu8.Hash(g)
+	if err != nil {
+		panic(err)
+	}
+	return h
+}
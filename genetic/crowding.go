@@ -0,0 +1,103 @@
+package genetic
+
+import (
+	"math"
+
+	"github.com/soypat/mu8"
+)
+
+// rival is the parent a child competes with during deterministic crowding.
+type rival[G mu8.Genome] struct {
+	ind       G
+	fitness   float64
+	violation float64
+	ok        bool
+}
+
+// SetCrowding enables deterministic crowding during Selection. Individuals are paired at random
+// and each pair breeds two children which compete with their most similar parent: during the next
+// call to Advance a child replaces its parent only if it is at least as fit. Since children replace
+// similar individuals niches are preserved. Crowding replaces the Selector, polygamy, elitism and
+// mating restrictions: every individual is a parent and the best individuals are never lost.
+// Crowding requires computing the distance between individuals. See [Population.SetDistance].
+// SteadyState offers crowding with [ReplaceCrowding].
+func (pop *Population[G]) SetCrowding(enable bool) { pop.crowding = enable }
+
+// crowdingSelection breeds the next generation by deterministic crowding. The parents
+// of each child are kept as rivals until the next call to Advance.
+func (pop *Population[G]) crowdingSelection(mutationRate float64) error {
+	N := len(pop.individuals)
+	perm := pop.rng.Perm(N)
+	newGeneration := make([]G, N)
+	rivals := make([]rival[G], N)
+	newCached := make([]bool, N)
+	newCache := make([]float64, N)
+	newParentFitness := slicemap(N, func(int) float64 { return math.NaN() })
+	if N%2 == 1 {
+		// Unpaired individual is carried unchanged into the next generation.
+		i := perm[N-1]
+		newGeneration[i] = pop.individuals[i]
+		newCached[i] = pop.cached[i]
+		newCache[i] = pop.cache[i]
+	}
+	for k := 0; k+1 < N; k += 2 {
+		p1, p2 := perm[k], perm[k+1]
+		a, b := pop.individuals[p1], pop.individuals[p2]
+		c1, err := pop.breed(a, b)
+		if err != nil {
+			return err
+		}
+		c2, err := pop.breed(b, a)
+		if err != nil {
+			return err
+		}
+		mu8.Mutate(c1, &pop.rng, mutationRate)
+		mu8.Mutate(c2, &pop.rng, mutationRate)
+		// Each child competes with its most similar parent.
+		var d [4]float64
+		for j, pair := range [4][2]G{{a, c1}, {b, c2}, {a, c2}, {b, c1}} {
+			d[j], err = pop.genomeDistance(pair[0], pair[1])
+			if err != nil {
+				return err
+			}
+		}
+		if d[0]+d[1] > d[2]+d[3] {
+			c1, c2 = c2, c1
+		}
+		for _, slot := range [2]struct {
+			parent int
+			child  G
+		}{{p1, c1}, {p2, c2}} {
+			i := slot.parent
+			newGeneration[i] = slot.child
+			newParentFitness[i] = pop.fitness[i]
+			rivals[i] = rival[G]{ind: pop.individuals[i], fitness: pop.fitness[i], violation: pop.violationOf(i), ok: true}
+		}
+	}
+	pop.individuals = newGeneration
+	pop.cached = newCached
+	pop.cache = newCache
+	pop.parentFitness = newParentFitness
+	pop.rivals = rivals
+	pop.evaluated = false
+	pop.gen++
+	return nil
+}
+
+// restoreRivals restores the parents of deterministic crowding that are fitter than their child
+// and reports whether the last Selection crowded. Must be called once the fitness of children is known.
+func (pop *Population[G]) restoreRivals() (crowded bool) {
+	crowded = pop.rivals != nil
+	for i, r := range pop.rivals {
+		if !r.ok || !pop.preferred(r.fitness, r.violation, pop.fitness[i], pop.violationOf(i)) {
+			continue
+		}
+		pop.individuals[i] = r.ind
+		pop.fitness[i] = r.fitness
+		if pop.violation != nil {
+			pop.violation[i] = r.violation
+		}
+	}
+	pop.rivals = nil
+	return crowded
+}
@@ -0,0 +1,125 @@
+package genetic
+
+import (
+	"github.com/soypat/mu8"
+)
+
+// Duplicates is the strategy used to handle children that are duplicates of other
+// individuals of the population before they are simulated.
+type Duplicates int
+
+const (
+	// KeepDuplicates keeps duplicate children. It is the default.
+	KeepDuplicates Duplicates = iota
+	// RebreedDuplicates discards duplicate children and breeds new ones in their place.
+	RebreedDuplicates
+	// MutateDuplicates mutates duplicate children until they are unique.
+	MutateDuplicates
+)
+
+// maxDuplicateAttempts bounds the number of times a duplicate child is rebred or mutated,
+// after which it is kept. Populations that converged may only be able to breed duplicates.
+const maxDuplicateAttempts = 8
+
+// SetDuplicates sets how children that are duplicates of other individuals in the population are handled
+// during Selection and SteadyState. Eliminating duplicates prevents the population from filling up
+// with clones of the champion and saves evaluations. Individuals are hashed using the Hasher interface
+// if implemented by G and by [mu8.Hash] otherwise. Individuals with the same hash are compared with
+// [mu8.Equal], which requires all Genes to implement [mu8.GeneHasher]. If G implements Hasher but its
+// Genes can't be compared, hash collisions are false positives: distinct children are treated as duplicates.
+//
+//	pop.SetDuplicates(genetic.RebreedDuplicates)
+func (pop *Population[G]) SetDuplicates(d Duplicates) {
+	if d != KeepDuplicates && d != RebreedDuplicates && d != MutateDuplicates {
+		panic("invalid duplicates strategy")
+	}
+	pop.duplicates = d
+}
+
+// uniqueOffspring breeds a child as offspring does and handles it according to the Population's
+// Duplicates strategy if it is a duplicate of an individual in seen. The child is then added to seen.
+func (pop *Population[G]) uniqueOffspring(seen genomeSet[G], mutationRate float64, polygamy int) (child G, parents []int, isClone bool, err error) {
+	child, parents, isClone, err = pop.offspring(mutationRate, polygamy)
+	if err != nil || pop.duplicates == KeepDuplicates {
+		return child, parents, isClone, err
+	}
+	for attempt := 0; attempt < maxDuplicateAttempts; attempt++ {
+		duplicate, err := seen.contains(child)
+		if err != nil {
+			return child, nil, false, err
+		} else if !duplicate {
+			break
+		}
+		switch pop.duplicates {
+		case RebreedDuplicates:
+			child, parents, isClone, err = pop.offspring(mutationRate, polygamy)
+			if err != nil {
+				return child, nil, false, err
+			}
+		case MutateDuplicates:
+			if mu8.Mutate(child, &pop.rng, mutationRate) > 0 {
+				isClone = false
+			}
+		}
+	}
+	return child, parents, isClone, seen.add(child)
+}
+
+// newGenomeSet returns a set of the individuals of the population at the given indices.
+// The set is empty and ignores additions if duplicates are kept.
+func (pop *Population[G]) newGenomeSet(indices []int) (genomeSet[G], error) {
+	if pop.duplicates == KeepDuplicates {
+		return genomeSet[G]{}, nil
+	}
+	seen := genomeSet[G]{set: make(map[uint64][]G)}
+	for _, i := range indices {
+		err := seen.add(pop.individuals[i])
+		if err != nil {
+			return seen, err
+		}
+	}
+	return seen, nil
+}
+
+// genomeSet is a set of individuals indexed by their hash.
+type genomeSet[G mu8.Genome] struct {
+	set map[uint64][]G
+}
+
+func (s genomeSet[G]) add(g G) error {
+	if s.set == nil {
+		return nil
+	}
+	h, err := genomeHash(g)
+	if err != nil {
+		return err
+	}
+	s.set[h] = append(s.set[h], g)
+	return nil
+}
+
+func (s genomeSet[G]) contains(g G) (bool, error) {
+	h, err := genomeHash(g)
+	if err != nil {
+		return false, err
+	}
+	_, isHasher := any(g).(Hasher)
+	for _, other := range s.set[h] {
+		equal, err := mu8.Equal(g, other)
+		if err != nil && isHasher {
+			// Genes can't be compared so hash collisions are reported as duplicates.
+			return true, nil
+		} else if err != nil || equal {
+			return equal, err
+		}
+	}
+	return false, nil
+}
+
+// genomeHash returns the hash of g using its Hash method if G implements Hasher and [mu8.Hash] otherwise.
+func genomeHash[G mu8.Genome](g G) (uint64, error) {
+	if h, ok := any(g).(Hasher); ok {
+		return h.Hash(), nil
+	}
+	return mu8.Hash(g)
+}
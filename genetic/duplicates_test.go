@@ -0,0 +1,84 @@
+package genetic
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/soypat/mu8"
+)
+
+func TestDuplicates(t *testing.T) {
+	const Nindividuals = 30
+	ctx := context.Background()
+	countDuplicates := func(pop *Population[*cfgenome]) (duplicates int) {
+		seen := make(map[[4]float64]bool)
+		for _, ind := range pop.Individuals() {
+			var key [4]float64
+			for i := range key {
+				key[i] = ind.genoma[i].Value()
+			}
+			if seen[key] {
+				duplicates++
+			}
+			seen[key] = true
+		}
+		return duplicates
+	}
+	var kept int
+	for _, strategy := range []Duplicates{KeepDuplicates, RebreedDuplicates, MutateDuplicates} {
+		pop := newTestPopulation(rand.NewSource(1), Nindividuals, 4)
+		pop.SetDuplicates(strategy)
+		// Low mutation rate and cloning parents breed many duplicates.
+		pop.SetSelector(Truncation{Fraction: 0.2})
+		total := 0
+		for gen := 0; gen < 10; gen++ {
+			err := pop.Advance(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = pop.Selection(0.1, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			total += countDuplicates(&pop)
+		}
+		if strategy == KeepDuplicates {
+			kept = total
+		} else if total*10 > kept {
+			// Some duplicates are expected since attempts at eliminating them are bounded.
+			t.Errorf("strategy %d: %d duplicates bred, %d when keeping duplicates", strategy, total, kept)
+		}
+	}
+}
+
+func TestGenomeSetHashCollision(t *testing.T) {
+	src := rand.NewSource(1)
+	a, b := &collidingGenome{newGenome(4)}, &collidingGenome{newGenome(4)}
+	mu8.Mutate(a, src, 1)
+	mu8.Mutate(b, src, 1)
+	seen := genomeSet[*collidingGenome]{set: make(map[uint64][]*collidingGenome)}
+	err := seen.add(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	duplicate, err := seen.contains(b)
+	if err != nil || duplicate {
+		t.Fatalf("distinct genome with colliding hash reported as duplicate (err=%v)", err)
+	}
+	err = mu8.Clone(b, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	duplicate, err = seen.contains(b)
+	if err != nil || !duplicate {
+		t.Fatalf("clone not reported as duplicate (err=%v)", err)
+	}
+}
+
+// collidingGenome is a Hasher whose hashes always collide.
+type collidingGenome struct {
+	*cfgenome
+}
+
+func (collidingGenome) Hash() uint64 { return 0 }
@@ -0,0 +1,141 @@
+package genetic
+
+import (
+	"sync"
+
+	"github.com/soypat/mu8"
+)
+
+// HallOfFame is an archive of the best distinct individuals found over a whole run.
+// Unlike the Champion, which is replaced whenever a better individual appears, the
+// HallOfFame keeps up to Size individuals sorted by fitness. Individuals are cloned
+// into the HallOfFame so that they are not modified by the genetic algorithm.
+// HallOfFame is safe for concurrent use so that it may be shared between islands.
+type HallOfFame[G mu8.Genome] struct {
+	mu      sync.Mutex
+	size    int
+	entries []HallOfFameEntry[G]
+}
+
+// HallOfFameEntry is an individual of the HallOfFame.
+type HallOfFameEntry[G mu8.Genome] struct {
+	Individual G
+	Fitness    float64
+	// Generation is the generation in which the individual was first found.
+	Generation int
+}
+
+// NewHallOfFame returns an empty HallOfFame that keeps up to size individuals.
+//
+//	hof := genetic.NewHallOfFame[*mygenome](10)
+//	pop.SetHallOfFame(hof)
+func NewHallOfFame[G mu8.Genome](size int) *HallOfFame[G] {
+	if size <= 0 {
+		panic("hall of fame size must be greater than 0")
+	}
+	return &HallOfFame[G]{size: size}
+}
+
+// Entries returns the individuals of the HallOfFame, best first. The returned
+// slice is a copy and individuals are not modified by the HallOfFame
+// once added, so it is safe to read while the genetic algorithm runs.
+func (h *HallOfFame[G]) Entries() []HallOfFameEntry[G] {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]HallOfFameEntry[G](nil), h.entries...)
+}
+
+// Len returns the number of individuals in the HallOfFame.
+func (h *HallOfFame[G]) Len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.entries)
+}
+
+// Size returns the maximum number of individuals kept by the HallOfFame.
+func (h *HallOfFame[G]) Size() int { return h.size }
+
+// qualifies reports whether an individual with the given fitness would enter the HallOfFame
+// if it is distinct from all its individuals. Must be called with h.mu held.
+func (h *HallOfFame[G]) qualifies(fitness float64, goal Goal) bool {
+	return len(h.entries) < h.size || goal.better(fitness, h.entries[len(h.entries)-1].Fitness)
+}
+
+// add inserts an entry keeping entries sorted best first. Entries with equal
+// fitness are kept in order of arrival. Must be called with h.mu held.
+func (h *HallOfFame[G]) add(entry HallOfFameEntry[G], goal Goal) {
+	pos := len(h.entries)
+	for pos > 0 && goal.better(entry.Fitness, h.entries[pos-1].Fitness) {
+		pos--
+	}
+	h.entries = append(h.entries, HallOfFameEntry[G]{})
+	copy(h.entries[pos+1:], h.entries[pos:])
+	h.entries[pos] = entry
+	if len(h.entries) > h.size {
+		h.entries = h.entries[:h.size]
+	}
+}
+
+// SetHallOfFame sets the HallOfFame updated after every call to Advance and SteadyState.
+// Only feasible individuals enter the HallOfFame. Passing nil, which is the default, disables it.
+//
+// Individuals are considered duplicates if they are equal according to [mu8.Equal] when Genes
+// implement [mu8.GeneHasher]. Otherwise they are duplicates if they have the same hash when G
+// implements [Hasher] or have zero distance, see [Population.SetDistance]. If none of these can be
+// computed individuals with equal fitness are considered duplicates. Individuals with different
+// hashes are never duplicates.
+func (pop *Population[G]) SetHallOfFame(h *HallOfFame[G]) { pop.hallOfFame = h }
+
+// updateHallOfFame considers the individuals at the given indices, best first, for entry into the HallOfFame.
+func (pop *Population[G]) updateHallOfFame(candidates []int) error {
+	h := pop.hallOfFame
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, i := range candidates {
+		if !pop.feasible(i) {
+			continue
+		}
+		fitness := pop.fitness[i]
+		if !h.qualifies(fitness, pop.goal) {
+			break // Candidates are sorted so the rest do not qualify either.
+		}
+		duplicate := false
+		for _, entry := range h.entries {
+			if pop.same(entry.Individual, entry.Fitness, pop.individuals[i], fitness) {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			continue
+		}
+		famous := pop.generator()
+		err := mu8.Clone(famous, pop.individuals[i])
+		if err != nil {
+			return err
+		}
+		h.add(HallOfFameEntry[G]{Individual: famous, Fitness: fitness, Generation: pop.gen}, pop.goal)
+	}
+	return nil
+}
+
+// same reports whether individuals a and b with fitness fa and fb have the same genetic content.
+func (pop *Population[G]) same(a G, fa float64, b G, fb float64) bool {
+	ha, hashable := any(a).(Hasher)
+	if hashable && ha.Hash() != any(b).(Hasher).Hash() {
+		return false
+	}
+	if equal, err := mu8.Equal(a, b); err == nil {
+		return equal
+	} else if hashable {
+		return true // Genes can't be compared so equal hashes are trusted.
+	}
+	d, err := pop.genomeDistance(a, b)
+	if err != nil {
+		return fa == fb
+	}
+	return d == 0
+}
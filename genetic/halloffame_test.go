@@ -0,0 +1,75 @@
+package genetic
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/soypat/mu8"
+)
+
+func TestHallOfFame(t *testing.T) {
+	const size = 5
+	ctx := context.Background()
+	pop := newTestPopulation(rand.NewSource(1), 20, 4)
+	hof := NewHallOfFame[*cfgenome](size)
+	pop.SetHallOfFame(hof)
+	for gen := 0; gen < 20; gen++ {
+		err := pop.Advance(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = pop.Selection(0.2, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries := hof.Entries()
+	if len(entries) != size {
+		t.Fatalf("expected %d entries, got %d", size, len(entries))
+	}
+	if entries[0].Fitness != pop.ChampionFitness() {
+		t.Errorf("best entry fitness %g does not match champion fitness %g", entries[0].Fitness, pop.ChampionFitness())
+	}
+	for k, entry := range entries {
+		if got := entry.Individual.Simulate(ctx); got != entry.Fitness {
+			t.Fatalf("entry %d fitness %g does not match its individual's %g", k, entry.Fitness, got)
+		}
+		if k > 0 && entry.Fitness > entries[k-1].Fitness {
+			t.Fatalf("entries not sorted by fitness: %g > %g", entry.Fitness, entries[k-1].Fitness)
+		}
+		for _, other := range entries[:k] {
+			d, err := mu8.Distance(entry.Individual, other.Individual)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d == 0 {
+				t.Fatalf("duplicate individual in hall of fame entry %d", k)
+			}
+		}
+		if entry.Generation < 0 || entry.Generation >= 20 {
+			t.Errorf("entry %d has bad generation %d", k, entry.Generation)
+		}
+	}
+}
+
+func TestHallOfFameHashCollision(t *testing.T) {
+	const size = 5
+	src := rand.NewSource(1)
+	individuals := make([]*collidingGenome, 20)
+	for i := range individuals {
+		individuals[i] = &collidingGenome{newGenome(4)}
+		mu8.Mutate(individuals[i], src, 1)
+	}
+	pop := NewPopulation(individuals, src, func() *collidingGenome { return &collidingGenome{newGenome(4)} })
+	hof := NewHallOfFame[*collidingGenome](size)
+	pop.SetHallOfFame(hof)
+	err := pop.Advance(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// All hashes collide but genes differ.
+	if hof.Len() != size {
+		t.Errorf("distinct individuals with colliding hashes dropped: expected %d entries, got %d", size, hof.Len())
+	}
+}
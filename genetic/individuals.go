@@ -0,0 +1,148 @@
+package genetic
+
+import (
+	"errors"
+	"math"
+
+	"github.com/soypat/mu8"
+)
+
+var (
+	errBadReplaceCount   = errors.New("bad number of individuals to replace: must be in range [0, Nindividuals-elitism]")
+	errBadPopulationSize = errors.New("bad population size: must be greater than elitism and zero")
+)
+
+// Inject clones seeds into the Population, replacing its worst individuals. Seeds may come
+// from a previous run or be designed by hand. The elite as set by SetElitism are never replaced.
+// If the Population has not been evaluated since the last call to Selection the last
+// individuals are replaced, which are never the elite since these are placed first.
+//
+// The fitness of injected individuals is unknown until the next call to Advance,
+// which must precede calls to Selection and SteadyState.
+func (pop *Population[G]) Inject(seeds ...G) error {
+	victims, err := pop.worst(len(seeds))
+	if err != nil {
+		return err
+	}
+	for k, i := range victims {
+		seed := pop.generator()
+		err = mu8.Clone(seed, seeds[k])
+		if err != nil {
+			return err
+		}
+		pop.replace(i, seed)
+	}
+	pop.evaluated = false
+	return nil
+}
+
+// Immigrate replaces the n worst individuals of the Population with random immigrants, that is to
+// say blank-slate individuals whose Genes have all been mutated. Random immigrants
+// restore diversity lost by the population. Individuals are chosen as in Inject.
+func (pop *Population[G]) Immigrate(n int) error {
+	victims, err := pop.worst(n)
+	if err != nil {
+		return err
+	}
+	for _, i := range victims {
+		pop.replace(i, pop.immigrant())
+	}
+	pop.evaluated = false
+	return nil
+}
+
+// Resize grows or shrinks the Population to n individuals. The Population grows by
+// adding random immigrants at the end and shrinks by removing its worst individuals, chosen as in Inject.
+// The order of remaining individuals is preserved. n must be greater than the elitism.
+func (pop *Population[G]) Resize(n int) error {
+	N := len(pop.individuals)
+	switch {
+	case n <= 0 || n <= pop.elitism:
+		return errBadPopulationSize
+	case n == N:
+		return nil
+	case n > N:
+		for i := N; i < n; i++ {
+			pop.individuals = append(pop.individuals, pop.immigrant())
+		}
+		grow := n - N
+		pop.fitness = append(pop.fitness, make([]float64, grow)...)
+		pop.weights = append(pop.weights, make([]float64, grow)...)
+		pop.cached = append(pop.cached, make([]bool, grow)...)
+		pop.cache = append(pop.cache, make([]float64, grow)...)
+		pop.parentFitness = append(pop.parentFitness, slicemap(grow, func(int) float64 { return math.NaN() })...)
+		if pop.violation != nil {
+			pop.violation = append(pop.violation, make([]float64, grow)...)
+			pop.violationCache = append(pop.violationCache, make([]float64, grow)...)
+		}
+	default:
+		removed, err := pop.worst(N - n)
+		if err != nil {
+			return err
+		}
+		keep := make([]int, 0, n)
+		for i := 0; i < N; i++ {
+			if !contains(removed, i) {
+				keep = append(keep, i)
+			}
+		}
+		pop.individuals = pick(pop.individuals, keep)
+		pop.fitness = pick(pop.fitness, keep)
+		pop.weights = pick(pop.weights, keep)
+		pop.cached = pick(pop.cached, keep)
+		pop.cache = pick(pop.cache, keep)
+		pop.parentFitness = pick(pop.parentFitness, keep)
+		if pop.violation != nil {
+			pop.violation = pick(pop.violation, keep)
+			pop.violationCache = pick(pop.violationCache, keep)
+		}
+	}
+	pop.rivals = nil // Rivals no longer match individuals.
+	pop.evaluated = false
+	return nil
+}
+
+// worst returns the indices of the n worst individuals, which never include the elite.
+func (pop *Population[G]) worst(n int) ([]int, error) {
+	N := len(pop.individuals)
+	if n < 0 || n > N-pop.elitism {
+		return nil, errBadReplaceCount
+	}
+	if !pop.evaluated {
+		// Elite are at the start of the population after Selection.
+		idx := make([]int, n)
+		for k := range idx {
+			idx[k] = N - n + k
+		}
+		return idx, nil
+	}
+	ranked := pop.ranking()
+	return ranked[N-n:], nil
+}
+
+// replace replaces the ith individual with an individual of unknown fitness.
+func (pop *Population[G]) replace(i int, individual G) {
+	pop.individuals[i] = individual
+	pop.weights[i] = 0
+	pop.cached[i] = false
+	pop.parentFitness[i] = math.NaN()
+	if pop.rivals != nil {
+		pop.rivals[i] = rival[G]{}
+	}
+}
+
+// immigrant returns a blank-slate individual with all its Genes mutated.
+func (pop *Population[G]) immigrant() G {
+	individual := pop.generator()
+	mu8.Mutate(individual, &pop.rng, 1)
+	return individual
+}
+
+// pick returns the elements of s at indices idx.
+func pick[T any](s []T, idx []int) []T {
+	picked := make([]T, len(idx))
+	for k, i := range idx {
+		picked[k] = s[i]
+	}
+	return picked
+}
@@ -0,0 +1,74 @@
+package genetic
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+)
+
+func TestInjectAndResize(t *testing.T) {
+	const Nindividuals = 20
+	ctx := context.Background()
+	pop := newTestPopulation(rand.NewSource(1), Nindividuals, 4)
+	pop.SetElitism(2)
+	pop.SetFitnessCache(true)
+	err := pop.Advance(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	champFitness := pop.ChampionFitness()
+	// Inject a perfect individual designed by hand.
+	seed := newGenome(4)
+	for i := range seed.genoma {
+		seed.genoma[i].SetValue(1)
+	}
+	err = pop.Inject(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pop.Selection(0.2, 1) != errNotEvaluated {
+		t.Fatal("expected Selection to require Advance after Inject")
+	}
+	err = pop.Immigrate(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pop.Immigrate(Nindividuals-1) != errBadReplaceCount {
+		t.Error("expected error replacing elite")
+	}
+	err = pop.Advance(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pop.ChampionFitness() <= champFitness || pop.ChampionFitness() != seed.Simulate(ctx) {
+		t.Fatalf("expected injected seed to become champion, got fitness %g", pop.ChampionFitness())
+	}
+
+	for _, size := range []int{30, 10, 3} {
+		err = pop.Resize(size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(pop.Individuals()) != size {
+			t.Fatalf("expected %d individuals, got %d", size, len(pop.Individuals()))
+		}
+		for gen := 0; gen < 3; gen++ {
+			err = pop.Advance(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, ind := range pop.Individuals() {
+				if ind.Simulate(ctx) != pop.fitness[i] {
+					t.Fatalf("size %d: fitness of individual %d out of sync", size, i)
+				}
+			}
+			err = pop.Selection(0.2, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if pop.Resize(2) != errBadPopulationSize {
+		t.Error("expected error shrinking population to elitism")
+	}
+}
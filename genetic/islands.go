@@ -6,6 +6,7 @@ import (
 	"math"
 	"math/rand"
 	"sync"
+	"time"
 
 	"github.com/soypat/mu8"
 )
@@ -15,13 +16,28 @@ import (
 type Islands[G mu8.Genome] struct {
 	islands []island[G]
 	rng     rand.Rand
-	// Migration Window, a buffer to keep best individual from each island.
-	mw []migrant[G]
+	src     rand.Source
+	// Migration Window, a buffer to keep best individuals from each island.
+	mw [][]migrant[G]
+	// migration configures how and when migrants are exchanged during Crossover.
+	migration Migration
+	// migrationSet is true once SetMigration is called. Until then
+	// islands exchange their champions as described in Crossover.
+	migrationSet bool
+	// epoch is the number of calls to Advance.
+	epoch int
+	// stats aggregated over all islands during last call to Advance.
+	stats Stats
+	// mu guards the champions published by islands so that
+	// they can be queried while islands are running.
+	mu *sync.RWMutex
 }
 
+// migrant is an individual selected for migration along with its fitness and constraint violation.
 type migrant[G mu8.Genome] struct {
-	ind    G
-	origin int
+	ind       G
+	fitness   float64
+	violation float64
 }
 
 // NewIslands simulates multiple populations which interchange
@@ -29,6 +45,9 @@ type migrant[G mu8.Genome] struct {
 // of Islands over just several Populations is that it provides readily
 // available multi-core execution of the genetic algorithm and whose final
 // result is the local optimum of all populations.
+//
+// If src implements mu8.SplitSource, such as mu8.Source, each island is given
+// an independent stream split from src.
 func NewIslands[G mu8.Genome](Nislands int, individuals []G, src rand.Source, newIndividual func() G) Islands[G] {
 	if Nislands <= 1 {
 		panic("need at least 2 islands")
@@ -60,13 +79,14 @@ func NewIslands[G mu8.Genome](Nislands int, individuals []G, src rand.Source, ne
 
 	islands := make([]island[G], Nislands)
 	for i := range islands {
-		islands[i] = newIsland(populations[i], rand.NewSource(src.Int63()), newIndividual)
-
+		islands[i] = newIsland(populations[i], islandSource(src), newIndividual)
 	}
 	return Islands[G]{
 		islands: islands,
-		mw:      make([]migrant[G], len(islands)),
+		mw:      make([][]migrant[G], len(islands)),
 		rng:     *rand.New(src),
+		src:     src,
+		mu:      new(sync.RWMutex),
 	}
 }
 
@@ -78,35 +98,346 @@ func (is Islands[G]) Populations() []Population[G] {
 	return pops
 }
 
+// Island returns the Population of the ith island so that islands can be configured
+// individually with Population setters, such as SetSelector or SetFitnessTransform.
+// Islands-wide setters override the configuration of all islands. The returned
+// Population must not be advanced nor modified while islands are running.
+//
+//	// Explorer island with a low selective pressure.
+//	isls.Island(0).SetSelector(genetic.Tournament{Size: 2})
+//	isls.SetIslandBreeding(0, 0.3, 1)
+//	// Exploiter island with a high selective pressure.
+//	isls.Island(1).SetSelector(genetic.Truncation{Fraction: 0.2})
+//	isls.SetIslandBreeding(1, 0.01, 2)
+func (is *Islands[G]) Island(i int) *Population[G] {
+	return &is.islands[i].Population
+}
+
+// SetIslandBreeding sets the mutation rate and polygamy used to breed the ith island, which
+// override the mutationRate and polygamy arguments of Advance and AdvanceAsync.
+func (is *Islands[G]) SetIslandBreeding(i int, mutationRate float64, polygamy int) {
+	if mutationRate <= 0 || mutationRate > 1 {
+		panic(errBadMutationRate.Error())
+	} else if polygamy < 0 {
+		panic(errBadPolygamy.Error())
+	}
+	isle := &is.islands[i]
+	isle.breedingRate, isle.breedingPolygamy, isle.customBreeding = mutationRate, polygamy, true
+}
+
+// SetSelector sets the parent selection strategy of all islands.
+// See [Population.SetSelector].
+func (is *Islands[G]) SetSelector(s Selector) {
+	for i := range is.islands {
+		is.islands[i].SetSelector(s)
+	}
+}
+
+// SetElitism sets the number of elite individuals carried
+// unchanged into the next generation on every island. See [Population.SetElitism].
+// Elitism of a single island is set through [Islands.Island]:
+//
+//	isls.SetElitism(0)
+//	isls.Island(0).SetElitism(2) // Only island 0 keeps its elite.
+func (is *Islands[G]) SetElitism(k int) {
+	for i := range is.islands {
+		is.islands[i].SetElitism(k)
+	}
+}
+
+// SetGoal sets the optimization direction of all islands. See [Population.SetGoal].
+func (is *Islands[G]) SetGoal(goal Goal) {
+	for i := range is.islands {
+		is.islands[i].SetGoal(goal)
+	}
+}
+
+// SetFitnessTransform sets the fitness transform of all islands.
+// See [Population.SetFitnessTransform].
+func (is *Islands[G]) SetFitnessTransform(t FitnessTransform) {
+	for i := range is.islands {
+		is.islands[i].SetFitnessTransform(t)
+	}
+}
+
+// SetDistance sets the distance function between individuals of all islands.
+// See [Population.SetDistance].
+func (is *Islands[G]) SetDistance(distance func(a, b G) float64) {
+	for i := range is.islands {
+		is.islands[i].SetDistance(distance)
+	}
+}
+
+// SetNiching sets the niching method of all islands. See [Population.SetNiching].
+func (is *Islands[G]) SetNiching(n Niching) {
+	for i := range is.islands {
+		is.islands[i].SetNiching(n)
+	}
+}
+
+// SetCrowding enables deterministic crowding on all islands. See [Population.SetCrowding].
+func (is *Islands[G]) SetCrowding(enable bool) {
+	for i := range is.islands {
+		is.islands[i].SetCrowding(enable)
+	}
+}
+
+// SetRepeatedParents sets whether parents may be repeated on all islands.
+// See [Population.SetRepeatedParents].
+func (is *Islands[G]) SetRepeatedParents(allow bool) {
+	for i := range is.islands {
+		is.islands[i].SetRepeatedParents(allow)
+	}
+}
+
+// SetMatingRestriction sets the mating restriction of all islands.
+// See [Population.SetMatingRestriction].
+func (is *Islands[G]) SetMatingRestriction(m MatingRestriction) {
+	for i := range is.islands {
+		is.islands[i].SetMatingRestriction(m)
+	}
+}
+
+// SetMutationController sets a mutation rate controller on each island. newController
+// is called once per island so that stateful controllers are not shared between islands.
+// Passing nil disables mutation rate control.
+// See [Population.SetMutationController].
+//
+//	isls.SetMutationController(func() genetic.MutationController {
+//		return &genetic.OneFifthRule{}
+//	})
+func (is *Islands[G]) SetMutationController(newController func() MutationController) {
+	for i := range is.islands {
+		var c MutationController
+		if newController != nil {
+			c = newController()
+		}
+		is.islands[i].SetMutationController(c)
+	}
+}
+
+// SetEvaluator sets the function used to compute the fitness of individuals of all islands.
+// It must be safe for concurrent use since islands are simulated concurrently.
+// See [Population.SetEvaluator].
+func (is *Islands[G]) SetEvaluator(f Evaluator[G]) {
+	for i := range is.islands {
+		is.islands[i].SetEvaluator(f)
+	}
+}
+
+// SetHallOfFame sets a HallOfFame shared by all islands.
+// See [Population.SetHallOfFame].
+func (is *Islands[G]) SetHallOfFame(h *HallOfFame[G]) {
+	for i := range is.islands {
+		is.islands[i].SetHallOfFame(h)
+	}
+}
+
+// SetFailurePolicy sets the failed evaluation policy of all islands.
+// See [Population.SetFailurePolicy].
+func (is *Islands[G]) SetFailurePolicy(p FailurePolicy) {
+	for i := range is.islands {
+		is.islands[i].SetFailurePolicy(p)
+	}
+}
+
+// SetConstraintHandler sets the constraint handler of each island. newHandler is called once
+// per island so that stateful handlers are not shared between islands. Passing nil restores
+// the default [FeasibilityRules]. See [Population.SetConstraintHandler].
+func (is *Islands[G]) SetConstraintHandler(newHandler func() ConstraintHandler) {
+	for i := range is.islands {
+		var h ConstraintHandler
+		if newHandler != nil {
+			h = newHandler()
+		}
+		is.islands[i].SetConstraintHandler(h)
+	}
+}
+
+// SetMigration sets the migration topology, interval, number of migrants and migration
+// policies used by Crossover. Once set, islands are left evaluated after Advance so that
+// migrants can be chosen and replace individuals based on their fitness. Until SetMigration
+// is called the champion of each island is sent to a random island on every Crossover
+// where it replaces the individual with the lowest selection weight.
+//
+//	isls.SetMigration(genetic.Migration{
+//		Topology:    genetic.Ring{},
+//		Interval:    2,
+//		Migrants:    3,
+//		Emigration:  genetic.EmigrateTournament{Size: 3},
+//		Immigration: genetic.ImmigrateMostSimilar{},
+//	})
+//
+// An error is returned and the migration is not set if m is not valid for the number of islands,
+// for example if a [Graph] topology sends the migrants of an island to itself.
+func (is *Islands[G]) SetMigration(m Migration) error {
+	err := m.validate(len(is.islands))
+	if err != nil {
+		return err
+	}
+	is.migration = m
+	is.migrationSet = true
+	return nil
+}
+
+// islandSource returns the rand.Source of a new island. Sources that implement
+// mu8.SplitSource are split so that each island has an independent stream.
+func islandSource(src rand.Source) rand.Source {
+	if splitter, ok := src.(mu8.SplitSource); ok {
+		return splitter.Split()
+	}
+	return rand.NewSource(src.Int63())
+}
+
 func newIsland[G mu8.Genome](individuals []G, src rand.Source, newIndividual func() G) island[G] {
 	return island[G]{
-		prevFitness: make([]float64, len(individuals)),
-		Population:  NewPopulation(individuals, src, newIndividual),
+		Population: NewPopulation(individuals, src, newIndividual),
 	}
 }
 
 type island[G mu8.Genome] struct {
 	Population[G]
+	// Fitness of individuals at the end of the previous epoch, used to compute attractiveness.
 	prevFitness []float64
-	attr        float64
+	// attr is the attractiveness of the island, guarded by Islands.mu.
+	attr float64
+	// Breeding parameters that override the arguments of Advance if customBreeding is set.
+	breedingRate     float64
+	breedingPolygamy int
+	customBreeding   bool
+	// Champion published after each generation, guarded by Islands.mu.
+	published        G
+	publishedFitness float64
+	hasPublished     bool
 }
 
-// receiveMigrant replaces individual with zero or minimum
-// fitness with the migrant
-func (is *island[G]) receiveMigrant(migrant G) {
+// generation runs one generation of the genetic algorithm on the island. Islands are left
+// evaluated between generations so that migrants can be chosen and replace individuals
+// based on fitness, so Selection is performed before Advance. If selectLast is set
+// Selection is also performed after Advance and the island is left unevaluated, as
+// required by the default migration which only exchanges champions.
+func (is *island[G]) generation(ctx context.Context, mutationRate float64, polygamy int, selectLast bool) error {
+	if is.customBreeding {
+		mutationRate, polygamy = is.breedingRate, is.breedingPolygamy
+	}
+	if is.evaluated {
+		err := is.Selection(mutationRate, polygamy)
+		if err != nil {
+			return err
+		}
+	}
+	err := is.Advance(ctx)
+	if err != nil || !selectLast {
+		return err
+	}
+	return is.Selection(mutationRate, polygamy)
+}
+
+// championMigrant returns a clone of the champion of the island.
+func (is *island[G]) championMigrant() ([]migrant[G], error) {
+	champ := is.generator()
+	err := mu8.Clone(champ, is.champ)
+	if err != nil {
+		return nil, err
+	}
+	return []migrant[G]{{ind: champ, fitness: is.champFitness}}, nil
+}
+
+// receiveMigrant replaces the individual with zero or minimum selection weight with
+// a clone of the migrant. The island need not be evaluated.
+func (is *island[G]) receiveMigrant(m migrant[G]) error {
 	minidx := -1
-	minFitness := is.fitness[0] + 1
-	for i := 0; i < len(is.fitness); i++ {
-		fitness := is.fitness[i]
-		if fitness == 0 {
+	minWeight := is.weights[0] + 1
+	for i, w := range is.weights {
+		if w == 0 {
 			minidx = i
 			break
-		} else if fitness < minFitness {
+		} else if w < minWeight {
 			minidx = i
-			minFitness = fitness
+			minWeight = w
 		}
 	}
-	mu8.Clone(is.individuals[minidx], migrant)
+	immigrant := is.generator()
+	err := mu8.Clone(immigrant, m.ind)
+	if err != nil {
+		return err
+	}
+	is.replace(minidx, immigrant)
+	return nil
+}
+
+// receiveMigrants replaces individuals chosen by the immigration policy with clones of the migrants.
+// Elite individuals and individuals replaced by a previous migrant are not replaced and
+// migrants in excess are discarded. The island must be evaluated and remains evaluated
+// since the fitness of migrants is known.
+func (is *island[G]) receiveMigrants(policy Immigration, migrants []migrant[G]) error {
+	if len(migrants) == 0 {
+		return nil
+	}
+	candidates := is.ranking()[is.elitism:]
+	for _, m := range migrants {
+		if len(candidates) == 0 {
+			break // No room left for migrants.
+		}
+		var distErr error
+		rank := policy.Replace(&is.rng, len(candidates), func(rank int) float64 {
+			d, err := is.genomeDistance(m.ind, is.individuals[candidates[rank]])
+			if err != nil && distErr == nil {
+				distErr = err
+			}
+			return d
+		})
+		if distErr != nil {
+			return distErr
+		} else if rank < 0 || rank >= len(candidates) {
+			return errBadImmigrantRank
+		}
+		i := candidates[rank]
+		immigrant := is.generator()
+		err := mu8.Clone(immigrant, m.ind)
+		if err != nil {
+			return err
+		}
+		is.replace(i, immigrant)
+		is.fitness[i] = m.fitness
+		is.cache[i] = m.fitness
+		is.cached[i] = true
+		if is.violation != nil {
+			is.violation[i] = m.violation
+			is.violationCache[i] = m.violation
+		}
+		candidates = append(candidates[:rank], candidates[rank+1:]...)
+	}
+	fitnessSum, err := is.computeWeights(false)
+	if err != nil {
+		return err
+	}
+	is.fitnessSum = fitnessSum
+	return nil
+}
+
+// emigrants returns clones of the n individuals chosen by the emigration policy.
+// The island must be evaluated.
+func (is *island[G]) emigrants(policy Emigration, n int) ([]migrant[G], error) {
+	ranked := is.ranking()
+	if n > len(ranked) {
+		n = len(ranked)
+	}
+	ranks := make([]int, n)
+	policy.Emigrants(&is.rng, ranks, len(ranked))
+	emigrants := make([]migrant[G], n)
+	for k, rank := range ranks {
+		if rank < 0 || rank >= len(ranked) {
+			return nil, errBadEmigrantRank
+		}
+		i := ranked[rank]
+		emigrants[k] = migrant[G]{ind: is.generator(), fitness: is.fitness[i], violation: is.violationOf(i)}
+		err := mu8.Clone(emigrants[k].ind, is.individuals[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return emigrants, nil
 }
 
 func (is *island[G]) Individuals() []G {
@@ -117,6 +448,10 @@ func (is *island[G]) Individuals() []G {
 // algorithm on each island. After Ngen generations elapse on each island
 // the champions of each island are selected for migration and interchange places
 // with other island champions. Crossover must be called to fulfill the migration.
+// If a Migration is set the migrants are instead selected by its Emigration policy and
+// islands are left evaluated, that is to say Selection is performed at the start of
+// each generation except the first. mutationRate and polygamy may be overridden per island,
+// see [Islands.SetIslandBreeding]. See [Islands.SetMigration].
 func (is *Islands[G]) Advance(ctx context.Context, mutationRate float64, polygamy, Ngen, Nconcurrent int) error {
 	I := len(is.islands)
 	switch {
@@ -132,6 +467,8 @@ func (is *Islands[G]) Advance(ctx context.Context, mutationRate float64, polygam
 		return ctx.Err()
 	}
 
+	start := time.Now()
+	startEvals := is.evaluations()
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
@@ -164,19 +501,23 @@ func (is *Islands[G]) Advance(ctx context.Context, mutationRate float64, polygam
 				}
 				wg.Done()
 			}()
+			isle := &is.islands[i]
 			for g := 0; g < Ngen; g++ {
 				checkin <- struct{}{}
-				err = is.islands[i].Advance(ctx)
-				if err != nil {
-					return err
-				}
-				err = is.islands[i].Selection(mutationRate, polygamy)
+				err = isle.generation(ctx, mutationRate, polygamy, !is.migrationSet)
 				if err != nil {
 					return err
 				}
+				is.publish(i)
 				<-checkin
 			}
-			return nil
+			is.updateAttractiveness(i)
+			if !is.migrationSet {
+				is.mw[i], err = isle.championMigrant()
+				return err
+			}
+			is.mw[i], err = isle.emigrants(is.migration.emigration(), is.migration.migrants())
+			return err
 		}()
 	}
 	wg.Wait()
@@ -186,75 +527,159 @@ func (is *Islands[G]) Advance(ctx context.Context, mutationRate float64, polygam
 		return <-errChan
 	}
 
-	// is.updateAttractiveness()
-	for i := 0; i < I; i++ {
-		mig := is.islands[i].generator()
-		errclone := mu8.Clone(mig, is.islands[i].Champion())
-		if errclone != nil {
-			return errclone
-		}
-		is.mw[i] = migrant[G]{mig, i}
-	}
-
+	is.stats = is.aggregateStats(is.evaluations()-startEvals, time.Since(start))
+	is.epoch++
 	return nil
 }
 
-// Crossover randomly distributes the selected migrants
-// across islands.
-func (is *Islands[G]) Crossover() {
+// evaluations returns the number of simulations performed on all islands.
+func (is *Islands[G]) evaluations() (evals int) {
+	for i := range is.islands {
+		evals += is.islands[i].evals
+	}
+	return evals
+}
+
+// Crossover sends the migrants selected during the last call to Advance to their destination
+// islands as determined by the migration Topology where they replace individuals chosen by the
+// Immigration policy. Migration only occurs every Interval calls to Advance. See [Islands.SetMigration].
+// If no Migration is set the champions are randomly distributed across islands.
+func (is *Islands[G]) Crossover() error {
 	I := len(is.islands)
-	// Perform crossover.
-	for i := 0; i < I; i++ {
-		migrant := is.mw[i]
-		for {
-			j := is.rng.Intn(I)
-			if migrant.origin != j {
-				is.islands[j].receiveMigrant(migrant.ind)
-				break
+	if !is.migrationSet {
+		for i := 0; i < I; i++ {
+			for _, m := range is.mw[i] {
+				j := RandomTopology{}.Destinations(&is.rng, i, I)[0]
+				err := is.islands[j].receiveMigrant(m)
+				if err != nil {
+					return fmt.Errorf("island (population) %d: %w", j, err)
+				}
 			}
 		}
+		return nil
+	}
+	if is.epoch%is.migration.interval() != 0 {
+		return nil
+	}
+	topology := is.migration.topology()
+	if a, ok := topology.(*Attractiveness); ok {
+		// Attractiveness may not be up to date if it was set after Advance or Islands were loaded.
+		for i := range is.islands {
+			a.update(i, I, is.islands[i].attr)
+		}
+	}
+	incoming := make([][]migrant[G], I)
+	for i := 0; i < I; i++ {
+		for _, j := range topology.Destinations(&is.rng, i, I) {
+			incoming[j] = append(incoming[j], is.mw[i]...)
+		}
 	}
+	immigration := is.migration.immigration()
+	for j := range incoming {
+		err := is.islands[j].receiveMigrants(immigration, incoming[j])
+		if err != nil {
+			return fmt.Errorf("island (population) %d: %w", j, err)
+		}
+	}
+	return nil
 }
 
 // Champion returns the individual with the best fitness among all islands.
+// It is safe to call while islands are running, see [Islands.AdvanceAsync].
 func (is *Islands[G]) Champion() G {
+	is.mu.RLock()
+	defer is.mu.RUnlock()
 	champi := is.champIdx()
-	return is.islands[champi].Champion()
+	return is.islands[champi].published
 }
 
 // Champion returns the best fitness among all island individuals.
+// It is safe to call while islands are running, see [Islands.AdvanceAsync].
 func (is *Islands[G]) ChampionFitness() float64 {
+	is.mu.RLock()
+	defer is.mu.RUnlock()
 	champi := is.champIdx()
-	return is.islands[champi].ChampionFitness()
+	return is.islands[champi].publishedFitness
 }
 
 func (is *Islands[G]) champIdx() int {
-	maxFitness := 0.
+	maxidx := is.bestIsland()
+	if maxidx == -1 {
+		panic("all fitnesses zero. can't select champion before Advance completed")
+	}
+	return maxidx
+}
+
+// bestIsland returns the index of the island with the best published champion or -1 if
+// no island has a champion. Must be called with is.mu held.
+func (is *Islands[G]) bestIsland() int {
 	maxidx := -1
 	for i := range is.islands {
-		champFitness := is.islands[i].ChampionFitness()
-		if champFitness > maxFitness {
-			maxFitness = champFitness
+		isle := &is.islands[i]
+		if !isle.hasPublished || (isle.goal == Maximize && isle.publishedFitness == 0) {
+			continue
+		}
+		if maxidx == -1 || isle.goal.better(isle.publishedFitness, is.islands[maxidx].publishedFitness) {
 			maxidx = i
 		}
 	}
-	if maxidx == -1 {
-		panic("all fitnesses zero. can't select champion before Advance completed")
-	}
 	return maxidx
 }
 
-func (is *Islands[G]) updateAttractiveness() {
-	I := len(is.islands)
-	for i := 0; i < I; i++ {
-		sum := 0.0
-		isle := &is.islands[i]
-		for k := range isle.prevFitness {
-			// iterate over individuals
-			sum += isle.prevFitness[k] - isle.fitness[k]
+// championFitness returns the champion fitness or zero if no champion has been found.
+func (is *Islands[G]) championFitness() float64 {
+	is.mu.RLock()
+	defer is.mu.RUnlock()
+	best := is.bestIsland()
+	if best == -1 {
+		return 0
+	}
+	return is.islands[best].publishedFitness
+}
+
+// publish makes the champion of the ith island available to Champion and ChampionFitness.
+// Must be called by the goroutine running the island.
+func (is *Islands[G]) publish(i int) {
+	isle := &is.islands[i]
+	is.mu.Lock()
+	defer is.mu.Unlock()
+	isle.published = isle.champ
+	isle.publishedFitness = isle.champFitness
+	isle.hasPublished = isle.hasChamp
+}
+
+// Attractiveness returns the attractiveness of each island, that is to say the mean improvement of
+// the fitness of its individuals during the last epoch, see [Attractiveness]. It is safe to call while islands are running.
+func (is *Islands[G]) Attractiveness() []float64 {
+	is.mu.RLock()
+	defer is.mu.RUnlock()
+	attr := make([]float64, len(is.islands))
+	for i := range is.islands {
+		attr[i] = is.islands[i].attr
+	}
+	return attr
+}
+
+// updateAttractiveness computes the attractiveness of the ith island at the end of an epoch
+// and informs the migration Topology if it is an [Attractiveness].
+// Must be called by the goroutine running the island.
+func (is *Islands[G]) updateAttractiveness(i int) {
+	isle := &is.islands[i]
+	attr := 0.0
+	if len(isle.prevFitness) > 0 {
+		prevMean, _ := meanStdDev(isle.prevFitness)
+		mean, _ := meanStdDev(isle.fitness)
+		attr = mean - prevMean
+		if isle.goal == Minimize {
+			attr = -attr
 		}
-		Sp := float64(len(isle.prevFitness)) // TODO: should this include only "live" (non-zero) fitnesses?
-		isle.attr = sum / Sp
+	}
+	isle.prevFitness = append(isle.prevFitness[:0], isle.fitness...)
+	is.mu.Lock()
+	isle.attr = attr
+	is.mu.Unlock()
+	if a, ok := is.migration.Topology.(*Attractiveness); ok {
+		a.update(i, len(is.islands), attr)
 	}
 }
 
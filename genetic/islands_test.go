@@ -2,9 +2,11 @@ package genetic
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"math/rand"
+	"sync/atomic"
 	"testing"
 
 	"github.com/soypat/mu8"
@@ -41,7 +43,10 @@ func TestIslandsRace(t *testing.T) {
 		if err != nil {
 			panic(err.Error())
 		}
-		isls.Crossover()
+		err = isls.Crossover()
+		if err != nil {
+			panic(err.Error())
+		}
 		champFitness := isls.ChampionFitness()
 		fmt.Printf("champ fitness=%.3f\n", champFitness)
 	}
@@ -70,3 +75,429 @@ func (g *cfgenome) Simulate(context.Context) (fitness float64) {
 	}
 	return fitness / float64(g.Len()) / 3
 }
+
+func TestIslandsElitism(t *testing.T) {
+	const (
+		Nislands     = 3
+		Nindividuals = 30
+		elitism      = 3
+	)
+	isls := newTestIslands(rand.NewSource(1), Nislands, Nindividuals, 4)
+	isls.SetElitism(0)
+	isls.Island(1).SetElitism(elitism)
+	for i, want := range []int{0, elitism, 0} {
+		if isls.islands[i].elitism != want {
+			t.Fatalf("island %d elitism %d, want %d", i, isls.islands[i].elitism, want)
+		}
+	}
+	best := 0.0
+	for epoch := 0; epoch < 5; epoch++ {
+		err := isls.Advance(context.Background(), 0.5, 1, 2, Nislands)
+		if err != nil {
+			t.Fatal(err)
+		}
+		isle := &isls.islands[1]
+		fittest := isle.fitness[isle.ranking()[0]]
+		if fittest < best {
+			t.Fatalf("epoch %d: best fitness of elitist island decreased from %g to %g", epoch, best, fittest)
+		}
+		best = fittest
+		err = isls.Crossover()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestTopologies(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, test := range []struct {
+		topology Topology
+		n, src   int
+		want     []int
+	}{
+		{topology: Ring{}, n: 4, src: 3, want: []int{0}},
+		{topology: BidirectionalRing{}, n: 4, src: 0, want: []int{3, 1}},
+		{topology: BidirectionalRing{}, n: 2, src: 0, want: []int{1}},
+		{topology: Torus{Width: 3}, n: 6, src: 4, want: []int{1, 3, 5}},
+		{topology: FullyConnected{}, n: 4, src: 2, want: []int{0, 1, 3}},
+		{topology: Star{Center: 1}, n: 4, src: 1, want: []int{0, 2, 3}},
+		{topology: Star{Center: 1}, n: 4, src: 3, want: []int{1}},
+		{topology: Graph{{1, 2}, {0}, {0}}, n: 3, src: 0, want: []int{1, 2}},
+	} {
+		got := test.topology.Destinations(rng, test.src, test.n)
+		if fmt.Sprint(got) != fmt.Sprint(test.want) {
+			t.Errorf("%T from %d of %d: got destinations %v, want %v", test.topology, test.src, test.n, got, test.want)
+		}
+	}
+	for i := 0; i < 100; i++ {
+		dst := RandomTopology{}.Destinations(rng, 2, 4)
+		if len(dst) != 1 || dst[0] == 2 || dst[0] < 0 || dst[0] >= 4 {
+			t.Fatalf("random topology bad destinations %v", dst)
+		}
+	}
+}
+
+func TestSetMigrationValidation(t *testing.T) {
+	const Nislands = 4
+	isls := newTestIslands(rand.NewSource(1), Nislands, 20, 4)
+	for _, test := range []struct {
+		m    Migration
+		want error
+	}{
+		{m: Migration{Topology: Graph{{1}, {2}, {3}, {0}}}},
+		{m: Migration{Topology: Torus{Width: 2}}},
+		{m: Migration{Interval: -1}, want: errBadMigration},
+		{m: Migration{Topology: Torus{Width: 3}}, want: errBadTorusWidth},
+		{m: Migration{Topology: Graph{{1}, {2}, {0}}}, want: errBadGraphLen},
+		{m: Migration{Topology: Graph{{1}, {2}, {4}, {0}}}, want: errBadGraphDest},
+		{m: Migration{Topology: Graph{{1}, {2}, {2, 3}, {0}}}, want: errGraphSelfLoop},
+	} {
+		err := isls.SetMigration(test.m)
+		if err != test.want {
+			t.Errorf("%+v: got error %v, want %v", test.m, err, test.want)
+		}
+	}
+	if _, ok := isls.migration.Topology.(Torus); !ok {
+		t.Error("invalid migration overrode last valid migration")
+	}
+}
+
+func TestMigration(t *testing.T) {
+	const (
+		Nislands     = 4
+		Nindividuals = 40
+		Migrants     = 3
+	)
+	src := rand.NewSource(1)
+	individuals := make([]*cfgenome, Nindividuals)
+	for i := range individuals {
+		individuals[i] = newGenome(4)
+		mu8.Mutate(individuals[i], src, 1)
+	}
+	isls := NewIslands(Nislands, individuals, src, func() *cfgenome { return newGenome(4) })
+	err := isls.SetMigration(Migration{Topology: Ring{}, Interval: 2, Migrants: Migrants})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	for epoch := 1; epoch <= 4; epoch++ {
+		err := isls.Advance(ctx, 0.1, 1, 2, Nislands)
+		if err != nil {
+			t.Fatal(err)
+		}
+		before := make([][]*cfgenome, Nislands)
+		for i := range isls.islands {
+			if len(isls.mw[i]) != Migrants {
+				t.Fatalf("island %d selected %d emigrants, want %d", i, len(isls.mw[i]), Migrants)
+			}
+			before[i] = append(before[i], isls.islands[i].individuals...)
+		}
+		err = isls.Crossover()
+		if err != nil {
+			t.Fatal(err)
+		}
+		received := make([]int, Nislands)
+		for i := range isls.islands {
+			isle := &isls.islands[i]
+			if !isle.evaluated {
+				t.Fatalf("island %d not evaluated after Crossover", i)
+			}
+			for j, ind := range isle.individuals {
+				if ind == before[i][j] {
+					continue
+				}
+				received[i]++
+				// Immigrants keep their fitness.
+				found := false
+				for _, m := range isls.mw[(i+Nislands-1)%Nislands] {
+					found = found || m.fitness == isle.fitness[j]
+				}
+				if !found {
+					t.Errorf("island %d immigrant fitness %g not found in migrants", i, isle.fitness[j])
+				}
+			}
+		}
+		want := 0
+		if epoch%2 == 0 {
+			want = Migrants
+		}
+		for i, got := range received {
+			if got != want {
+				t.Errorf("epoch %d: island %d received %d migrants, want %d", epoch, i, got, want)
+			}
+		}
+	}
+}
+
+func TestDefaultMigration(t *testing.T) {
+	const Nislands = 4
+	isls := newTestIslands(rand.NewSource(1), Nislands, 40, 4)
+	ctx := context.Background()
+	for epoch := 0; epoch < 3; epoch++ {
+		err := isls.Advance(ctx, 0.1, 1, 2, Nislands)
+		if err != nil {
+			t.Fatal(err)
+		}
+		before := make([][]*cfgenome, Nislands)
+		for i := range isls.islands {
+			isle := &isls.islands[i]
+			if isle.evaluated {
+				t.Fatalf("island %d evaluated after Advance without Migration", i)
+			}
+			if len(isls.mw[i]) != 1 || isls.mw[i][0].fitness != isle.champFitness {
+				t.Fatalf("island %d did not select its champion for migration", i)
+			}
+			before[i] = append(before[i], isle.individuals...)
+		}
+		err = isls.Crossover()
+		if err != nil {
+			t.Fatal(err)
+		}
+		received := 0
+		for i := range isls.islands {
+			isle := &isls.islands[i]
+			for j, ind := range isle.individuals {
+				if ind == before[i][j] {
+					continue
+				}
+				received++
+				if isle.cached[j] {
+					t.Errorf("island %d immigrant is cached", i)
+				}
+				found := false
+				for k := range isls.mw {
+					eq, err := mu8.Equal(ind, isls.mw[k][0].ind)
+					found = found || (k != i && err == nil && eq)
+				}
+				if !found {
+					t.Errorf("island %d immigrant is not a champion of another island", i)
+				}
+			}
+		}
+		if received == 0 || received > Nislands {
+			t.Errorf("epoch %d: islands received %d migrants, want 1 to %d", epoch, received, Nislands)
+		}
+	}
+}
+
+func TestMigrationPolicies(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	const n = 10
+	for _, policy := range []Emigration{EmigrateBest{}, EmigrateRandom{}, EmigrateTournament{Size: 3}} {
+		dst := make([]int, n)
+		policy.Emigrants(rng, dst, n)
+		seen := make(map[int]bool)
+		for _, rank := range dst {
+			if rank < 0 || rank >= n || seen[rank] {
+				t.Fatalf("%T: bad emigrant ranks %v", policy, dst)
+			}
+			seen[rank] = true
+		}
+	}
+	best := make([]int, 3)
+	EmigrateBest{}.Emigrants(rng, best, n)
+	if fmt.Sprint(best) != "[0 1 2]" {
+		t.Errorf("best emigrants got ranks %v", best)
+	}
+
+	distance := func(rank int) float64 { return math.Abs(float64(rank - 4)) }
+	if got := (ImmigrateWorst{}).Replace(rng, n, distance); got != n-1 {
+		t.Errorf("worst immigration replaced rank %d, want %d", got, n-1)
+	}
+	if got := (ImmigrateMostSimilar{}).Replace(rng, n, distance); got != 4 {
+		t.Errorf("most similar immigration replaced rank %d, want 4", got)
+	}
+	for i := 0; i < 100; i++ {
+		if got := (ImmigrateRandom{}).Replace(rng, n, distance); got < 0 || got >= n {
+			t.Fatalf("random immigration replaced rank %d out of range", got)
+		}
+	}
+}
+
+func TestAdvanceAsync(t *testing.T) {
+	const (
+		Nislands     = 4
+		Nindividuals = 80
+		genomelen    = 8
+	)
+	isls := newTestIslands(rand.NewSource(1), Nislands, Nindividuals, genomelen)
+	err := isls.SetMigration(Migration{Topology: FullyConnected{}, Migrants: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Stop islands after a fixed budget of evaluations instead of a timeout.
+	const budget = 40 * Nindividuals
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var evals int32
+	isls.SetEvaluator(func(_ context.Context, g *cfgenome) float64 {
+		if atomic.AddInt32(&evals, 1) == budget {
+			cancel()
+		}
+		return g.Simulate(context.Background())
+	})
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		// Query champion while islands run to catch data races.
+		defer close(done)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			if isls.championFitness() > 0 {
+				_ = isls.Champion()
+			}
+		}
+	}()
+	err = isls.AdvanceAsync(ctx, 0.1, 1, 3)
+	close(stop)
+	<-done
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&evals); n < budget {
+		t.Errorf("islands stopped after %d evaluations, before budget of %d", n, budget)
+	}
+	if isls.ChampionFitness() <= 0 {
+		t.Error("expected positive champion fitness after asynchronous run")
+	}
+	// Islands may be advanced synchronously after an asynchronous run.
+	err = isls.Advance(context.Background(), 0.1, 1, 2, Nislands)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Failure of one island stops all islands.
+	isls = newTestIslands(rand.NewSource(1), Nislands, Nindividuals, genomelen)
+	var calls int32
+	isls.SetEvaluator(func(_ context.Context, g *cfgenome) float64 {
+		if atomic.AddInt32(&calls, 1) > 10*Nindividuals {
+			return math.NaN()
+		}
+		return g.Simulate(context.Background())
+	})
+	err = isls.AdvanceAsync(context.Background(), 0.1, 1, 3)
+	if !errors.Is(err, errInvalidFitness) {
+		t.Errorf("expected invalid fitness error, got %v", err)
+	}
+}
+
+func TestHeterogeneousIslands(t *testing.T) {
+	const (
+		Nislands     = 3
+		Nindividuals = 60
+		genomelen    = 8
+	)
+	isls := newTestIslands(rand.NewSource(1), Nislands, Nindividuals, genomelen)
+	// Explorer island.
+	isls.Island(0).SetSelector(Tournament{Size: 2})
+	isls.SetIslandBreeding(0, 0.5, 0)
+	// Exploiter island.
+	isls.Island(1).SetSelector(Truncation{Fraction: 0.2})
+	isls.Island(1).SetFitnessTransform(LinearScaling{})
+	isls.SetIslandBreeding(1, 0.01, 2)
+	const mutationRate = 0.1
+	for epoch := 0; epoch < 3; epoch++ {
+		err := isls.Advance(context.Background(), mutationRate, 1, 3, Nislands)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = isls.Crossover()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, want := range []float64{0.5, 0.01, mutationRate} {
+		got := isls.Island(i).Stats().MutationRate
+		if got != want {
+			t.Errorf("island %d bred with mutation rate %g, want %g", i, got, want)
+		}
+	}
+}
+
+func TestIslandsDefaultUnchanged(t *testing.T) {
+	// Setting per-island breeding to the Advance arguments must not alter the default run.
+	const (
+		Nislands     = 3
+		mutationRate = 0.1
+		polygamy     = 1
+	)
+	run := func(custom bool) (champs []float64) {
+		isls := newTestIslands(rand.NewSource(1), Nislands, 30, 4)
+		if custom {
+			for i := 0; i < Nislands; i++ {
+				isls.SetIslandBreeding(i, mutationRate, polygamy)
+			}
+		}
+		for epoch := 0; epoch < 4; epoch++ {
+			err := isls.Advance(context.Background(), mutationRate, polygamy, 2, Nislands)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = isls.Crossover()
+			if err != nil {
+				t.Fatal(err)
+			}
+			champs = append(champs, isls.ChampionFitness())
+		}
+		return champs
+	}
+	want, got := run(false), run(true)
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("per-island breeding changed champions: got %v, want %v", got, want)
+	}
+}
+
+func TestAttractiveness(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	a := &Attractiveness{Exploration: 1e-9}
+	for i, attr := range []float64{0, 5, -1, 0} {
+		a.update(i, 4, attr)
+	}
+	for k := 0; k < 100; k++ {
+		// Only island 1 is improving.
+		if dst := a.Destinations(rng, 0, 4); len(dst) != 1 || dst[0] != 1 {
+			t.Fatalf("expected migrants sent to improving island 1, got %v", dst)
+		}
+		// Source is never a destination: no other island is improving.
+		if dst := a.Destinations(rng, 1, 4); len(dst) != 1 || dst[0] == 1 {
+			t.Fatalf("bad destinations %v for island 1", dst)
+		}
+	}
+
+	const (
+		Nislands     = 4
+		Nindividuals = 80
+		genomelen    = 8
+	)
+	isls := newTestIslands(rand.NewSource(1), Nislands, Nindividuals, genomelen)
+	err := isls.SetMigration(Migration{Topology: &Attractiveness{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	improving := false
+	for epoch := 0; epoch < 4; epoch++ {
+		err := isls.Advance(context.Background(), 0.1, 1, 3, Nislands)
+		if err != nil {
+			t.Fatal(err)
+		}
+		attr := isls.Attractiveness()
+		if epoch == 0 && fmt.Sprint(attr) != "[0 0 0 0]" {
+			t.Errorf("attractiveness must be zero after first epoch, got %v", attr)
+		}
+		for _, a := range attr {
+			improving = improving || a > 0
+		}
+		err = isls.Crossover()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !improving {
+		t.Error("expected an island to improve")
+	}
+}
@@ -0,0 +1,143 @@
+package genetic
+
+import "math"
+
+// MatingRestriction restricts which individuals may mate with the first parent of a child.
+// For each mate of the first parent, Candidates individuals are drawn using the
+// Population's Selector and the restriction chooses among them based on their
+// distance to the first parent. See [Population.SetDistance].
+type MatingRestriction interface {
+	// Candidates returns the number of mate candidates drawn for each mate. Must be positive.
+	Candidates() int
+	// Choose returns the index into distances of the chosen mate, or -1 if no
+	// candidate is acceptable, in which case the child has one less parent.
+	// distances contains the distance of each candidate to the first parent.
+	Choose(distances []float64) int
+}
+
+// Compile-time checks of interface implementation.
+var (
+	_ MatingRestriction = IncestPrevention{}
+	_ MatingRestriction = Assortative{}
+	_ MatingRestriction = Disassortative{}
+)
+
+// IncestPrevention prevents individuals that are closer than MinDistance from mating.
+// Since similar individuals usually share ancestry this prevents inbreeding and
+// slows down convergence of the population.
+type IncestPrevention struct {
+	// MinDistance is the minimum distance between mates.
+	MinDistance float64
+	// Tries is the number of candidates considered for each mate before giving up.
+	// Zero value is treated as 3.
+	Tries int
+}
+
+// Candidates implements the [MatingRestriction] interface.
+func (ip IncestPrevention) Candidates() int {
+	if ip.Tries <= 0 {
+		return 3
+	}
+	return ip.Tries
+}
+
+// Choose implements the [MatingRestriction] interface. It returns the first candidate
+// not closer than MinDistance.
+func (ip IncestPrevention) Choose(distances []float64) int {
+	for i, d := range distances {
+		if d >= ip.MinDistance {
+			return i
+		}
+	}
+	return -1
+}
+
+// Assortative implements positive assortative mating: the candidate most similar
+// to the first parent is chosen as mate, which favors exploitation of niches.
+type Assortative struct {
+	// Size is the number of candidates per mate. Zero value is treated as 3.
+	Size int
+}
+
+// Candidates implements the [MatingRestriction] interface.
+func (a Assortative) Candidates() int {
+	if a.Size <= 0 {
+		return 3
+	}
+	return a.Size
+}
+
+// Choose implements the [MatingRestriction] interface.
+func (Assortative) Choose(distances []float64) int {
+	best, bestDist := -1, math.Inf(1)
+	for i, d := range distances {
+		if d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	return best
+}
+
+// Disassortative implements negative assortative mating: the candidate least similar
+// to the first parent is chosen as mate, which favors exploration.
+type Disassortative struct {
+	// Size is the number of candidates per mate. Zero value is treated as 3.
+	Size int
+}
+
+// Candidates implements the [MatingRestriction] interface.
+func (d Disassortative) Candidates() int {
+	if d.Size <= 0 {
+		return 3
+	}
+	return d.Size
+}
+
+// Choose implements the [MatingRestriction] interface.
+func (Disassortative) Choose(distances []float64) int {
+	best, bestDist := -1, math.Inf(-1)
+	for i, d := range distances {
+		if d > bestDist {
+			best, bestDist = i, d
+		}
+	}
+	return best
+}
+
+// SetRepeatedParents sets whether an individual may be selected more than once as
+// parent of the same child. By default parents are distinct so that individuals
+// do not splice with themselves.
+func (pop *Population[G]) SetRepeatedParents(allow bool) { pop.repeatParents = allow }
+
+// SetMatingRestriction sets the mating restriction used during breeding.
+// Passing nil, which is the default, disables mating restrictions.
+//
+//	pop.SetMatingRestriction(genetic.IncestPrevention{MinDistance: 0.1})
+func (pop *Population[G]) SetMatingRestriction(m MatingRestriction) {
+	if m != nil && m.Candidates() <= 0 {
+		panic("mating restriction candidates must be positive")
+	}
+	pop.mating = m
+}
+
+// chooseMate draws mate candidates into the candidates buffer and returns
+// the chosen mate of parents[0] or an empty slice if none was acceptable.
+func (pop *Population[G]) chooseMate(parents, candidates []int) ([]int, error) {
+	candidates = pop.sampleParents(candidates, parents)
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+	distances := make([]float64, len(candidates))
+	for k, idx := range candidates {
+		d, err := pop.genomeDistance(pop.individuals[parents[0]], pop.individuals[idx])
+		if err != nil {
+			return nil, err
+		}
+		distances[k] = d
+	}
+	choice := pop.mating.Choose(distances)
+	if choice < 0 {
+		return nil, nil
+	}
+	return candidates[choice : choice+1], nil
+}
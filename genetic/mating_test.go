@@ -0,0 +1,74 @@
+package genetic
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+)
+
+func TestDistinctParents(t *testing.T) {
+	const sample = 4
+	ctx := context.Background()
+	pop := newTestPopulation(rand.NewSource(1), 20, 4)
+	// Tournament selection of a small population picks the same individuals often.
+	pop.SetSelector(Tournament{Size: 5})
+	err := pop.Advance(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		parents, err := pop.selectParents(sample)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(parents) == 0 {
+			t.Fatal("no parents selected")
+		}
+		for k, idx := range parents {
+			if contains(parents[:k], idx) {
+				t.Fatalf("repeated parent %d in %v", idx, parents)
+			}
+		}
+	}
+	pop.SetRepeatedParents(true)
+	for i := 0; i < 100; i++ {
+		parents, _ := pop.selectParents(sample)
+		if len(parents) != sample {
+			t.Fatalf("expected %d parents with repetition allowed, got %d", sample, len(parents))
+		}
+	}
+}
+
+func TestMatingRestrictions(t *testing.T) {
+	distances := []float64{0.5, 0.1, 2, 1}
+	for _, test := range []struct {
+		m      MatingRestriction
+		expect int
+	}{
+		{m: IncestPrevention{MinDistance: 0.8}, expect: 2},
+		{m: IncestPrevention{MinDistance: 3}, expect: -1},
+		{m: Assortative{}, expect: 1},
+		{m: Disassortative{}, expect: 2},
+	} {
+		got := test.m.Choose(distances)
+		if got != test.expect {
+			t.Errorf("%#v: chose %d, expected %d", test.m, got, test.expect)
+		}
+	}
+
+	ctx := context.Background()
+	for _, m := range []MatingRestriction{IncestPrevention{MinDistance: 0.1}, Assortative{}, Disassortative{Size: 5}} {
+		pop := newTestPopulation(rand.NewSource(1), 20, 4)
+		pop.SetMatingRestriction(m)
+		for gen := 0; gen < 10; gen++ {
+			err := pop.Advance(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = pop.Selection(0.2, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package genetic
+
+import (
+	"errors"
+	"math/rand"
+)
+
+var (
+	errBadEmigrantRank  = errors.New("emigration policy returned a rank out of range of individuals")
+	errBadImmigrantRank = errors.New("immigration policy returned a rank out of range of candidates")
+	errBadMigration     = errors.New("bad migration: interval and migrants must be non-negative")
+	errBadTorusWidth    = errors.New("bad torus topology: number of islands must be a multiple of torus width")
+	errBadGraphLen      = errors.New("bad graph topology: must have one adjacency list per island")
+	errBadGraphDest     = errors.New("bad graph topology: destination out of range of islands")
+	errGraphSelfLoop    = errors.New("bad graph topology: island sends migrants to itself")
+)
+
+// Migration configures the exchange of individuals between islands during Crossover.
+// Its zero value sends the best individual of each island to a random island. Islands
+// without a Migration set through [Islands.SetMigration] exchange their champions instead.
+type Migration struct {
+	// Topology determines which islands receive the migrants of each island.
+	// If nil, the default, migrants are sent to a random island.
+	Topology Topology
+	// Interval is the number of calls to Advance between migrations.
+	// If zero, migration occurs on every call to Crossover.
+	Interval int
+	// Migrants is the number of individuals of each island sent to each destination island.
+	// If zero, only one individual emigrates.
+	Migrants int
+	// Emigration chooses the individuals that emigrate. If nil, the default,
+	// the best individuals emigrate.
+	Emigration Emigration
+	// Immigration chooses the individuals replaced by immigrants. If nil, the default,
+	// immigrants replace the worst individuals.
+	Immigration Immigration
+}
+
+// validate checks the migration is valid for n islands.
+func (m Migration) validate(n int) error {
+	if m.Interval < 0 || m.Migrants < 0 {
+		return errBadMigration
+	}
+	switch t := m.Topology.(type) {
+	case Torus:
+		if t.Width <= 0 || n%t.Width != 0 {
+			return errBadTorusWidth
+		}
+	case Graph:
+		return t.validate(n)
+	}
+	return nil
+}
+
+func (m Migration) topology() Topology {
+	if m.Topology == nil {
+		return RandomTopology{}
+	}
+	return m.Topology
+}
+
+func (m Migration) interval() int {
+	if m.Interval == 0 {
+		return 1
+	}
+	return m.Interval
+}
+
+func (m Migration) migrants() int {
+	if m.Migrants == 0 {
+		return 1
+	}
+	return m.Migrants
+}
+
+func (m Migration) emigration() Emigration {
+	if m.Emigration == nil {
+		return EmigrateBest{}
+	}
+	return m.Emigration
+}
+
+func (m Migration) immigration() Immigration {
+	if m.Immigration == nil {
+		return ImmigrateWorst{}
+	}
+	return m.Immigration
+}
+
+// Emigration chooses the individuals of an island that emigrate.
+type Emigration interface {
+	// Emigrants fills dst with the ranks of the individuals that emigrate among n individuals
+	// ranked from best (rank 0) to worst (rank n-1). Ranks must not be repeated in dst.
+	Emigrants(rng *rand.Rand, dst []int, n int)
+}
+
+// Immigration chooses the individuals of an island replaced by immigrants.
+type Immigration interface {
+	// Replace returns the rank of the individual replaced by an immigrant among n candidates
+	// ranked from best (rank 0) to worst (rank n-1). distance returns the distance between
+	// the immigrant and the candidate of the given rank. The elite and individuals already
+	// replaced by other immigrants are not candidates.
+	Replace(rng *rand.Rand, n int, distance func(rank int) float64) int
+}
+
+// Compile-time checks of interface implementation.
+var (
+	_ Emigration  = EmigrateBest{}
+	_ Emigration  = EmigrateRandom{}
+	_ Emigration  = EmigrateTournament{}
+	_ Immigration = ImmigrateWorst{}
+	_ Immigration = ImmigrateRandom{}
+	_ Immigration = ImmigrateMostSimilar{}
+)
+
+// EmigrateBest sends the best individuals of an island. It is the default Emigration.
+type EmigrateBest struct{}
+
+// Emigrants implements the [Emigration] interface.
+func (EmigrateBest) Emigrants(_ *rand.Rand, dst []int, n int) {
+	for k := range dst {
+		dst[k] = k
+	}
+}
+
+// EmigrateRandom sends individuals chosen uniformly at random, which
+// spreads diversity between islands rather than good solutions.
+type EmigrateRandom struct{}
+
+// Emigrants implements the [Emigration] interface.
+func (EmigrateRandom) Emigrants(rng *rand.Rand, dst []int, n int) {
+	copy(dst, rng.Perm(n))
+}
+
+// EmigrateTournament sends the winners of tournaments between individuals
+// sampled uniformly from those that have not emigrated yet.
+type EmigrateTournament struct {
+	// Size is the number of contestants per tournament. Sizes below 2 are treated as 2.
+	Size int
+}
+
+// Emigrants implements the [Emigration] interface.
+func (t EmigrateTournament) Emigrants(rng *rand.Rand, dst []int, n int) {
+	size := t.Size
+	if size < 2 {
+		size = 2
+	}
+	remaining := make([]int, n)
+	for i := range remaining {
+		remaining[i] = i
+	}
+	for k := range dst {
+		winner := rng.Intn(len(remaining))
+		for c := 1; c < size; c++ {
+			contestant := rng.Intn(len(remaining))
+			if remaining[contestant] < remaining[winner] {
+				winner = contestant
+			}
+		}
+		dst[k] = remaining[winner]
+		remaining[winner] = remaining[len(remaining)-1]
+		remaining = remaining[:len(remaining)-1]
+	}
+}
+
+// ImmigrateWorst replaces the worst individuals. It is the default Immigration.
+type ImmigrateWorst struct{}
+
+// Replace implements the [Immigration] interface.
+func (ImmigrateWorst) Replace(_ *rand.Rand, n int, _ func(int) float64) int { return n - 1 }
+
+// ImmigrateRandom replaces individuals chosen uniformly at random.
+type ImmigrateRandom struct{}
+
+// Replace implements the [Immigration] interface.
+func (ImmigrateRandom) Replace(rng *rand.Rand, n int, _ func(int) float64) int { return rng.Intn(n) }
+
+// ImmigrateMostSimilar replaces the individual closest to the immigrant which preserves
+// the diversity of the receiving island. Requires the distance between individuals
+// to be computable, see [Population.SetDistance].
+type ImmigrateMostSimilar struct{}
+
+// Replace implements the [Immigration] interface.
+func (ImmigrateMostSimilar) Replace(_ *rand.Rand, n int, distance func(int) float64) int {
+	closest, minDist := 0, distance(0)
+	for rank := 1; rank < n; rank++ {
+		if d := distance(rank); d < minDist {
+			closest, minDist = rank, d
+		}
+	}
+	return closest
+}
@@ -0,0 +1,149 @@
+package genetic
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// MutationController adapts the mutation rate of a Population between generations.
+// Stateful controllers should not be shared between Populations.
+type MutationController interface {
+	// MutationRate returns the mutation rate used to breed the next generation.
+	// base is the mutation rate passed to Selection or SteadyState and last holds the Stats
+	// of the last simulated generation. Rates above 1 are clamped to 1.
+	MutationRate(base float64, last Stats) float64
+}
+
+// Compile-time checks of interface implementation.
+var (
+	_ MutationController = (*OneFifthRule)(nil)
+	_ MutationController = DiversityControl{}
+	_ MutationController = LinearDecay{}
+	_ MutationController = ExponentialDecay{}
+)
+
+// OneFifthRule implements Rechenberg's 1/5 success rule. If more than a fifth of the
+// offspring are fitter than their parents the mutation rate is increased to explore further,
+// if less than a fifth are fitter it is decreased. See [Stats.SuccessRate].
+type OneFifthRule struct {
+	// Factor by which the mutation rate is multiplied (or divided) each generation. Must be in range (0, 1).
+	// Zero value is treated as 0.85.
+	Factor float64
+	// Min and Max bound the mutation rate. Zero values are treated as 0.001 and 1, respectively.
+	Min, Max float64
+	// scaleMinus1 is the ratio between the mutation rate and base rate, minus 1.
+	scaleMinus1 float64
+}
+
+// MarshalBinary saves the state of the rule so that it can be checkpointed.
+func (r *OneFifthRule) MarshalBinary() ([]byte, error) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], math.Float64bits(r.scaleMinus1))
+	return b[:], nil
+}
+
+// UnmarshalBinary restores the state saved by MarshalBinary.
+func (r *OneFifthRule) UnmarshalBinary(data []byte) error {
+	if len(data) != 8 {
+		return errBadCheckpoint
+	}
+	r.scaleMinus1 = math.Float64frombits(binary.LittleEndian.Uint64(data))
+	return nil
+}
+
+// MutationRate implements the [MutationController] interface.
+func (r *OneFifthRule) MutationRate(base float64, last Stats) float64 {
+	factor := r.Factor
+	if factor == 0 {
+		factor = 0.85
+	} else if factor <= 0 || factor >= 1 {
+		panic("one fifth rule factor must be in range (0, 1)")
+	}
+	scale := r.scaleMinus1 + 1
+	switch {
+	case last.Evaluations == 0:
+		// No information gained from last generation.
+	case last.SuccessRate > 0.2:
+		scale /= factor
+	case last.SuccessRate < 0.2:
+		scale *= factor
+	}
+	rate := clampRate(base*scale, r.Min, r.Max)
+	r.scaleMinus1 = rate/base - 1 // Prevent scale from growing beyond bounds.
+	return rate
+}
+
+// DiversityControl increases the mutation rate as the population loses diversity.
+// Diversity is measured as the coefficient of variation of fitness (StdDev/|Mean|) and
+// the mutation rate is the base rate scaled by Target divided by the diversity.
+type DiversityControl struct {
+	// Target is the diversity at which the base mutation rate is used. Zero value is treated as 0.1.
+	Target float64
+	// Min and Max bound the mutation rate. Zero values are treated as 0.001 and 1, respectively.
+	Min, Max float64
+}
+
+// MutationRate implements the [MutationController] interface.
+func (dc DiversityControl) MutationRate(base float64, last Stats) float64 {
+	target := dc.Target
+	if target == 0 {
+		target = 0.1
+	}
+	diversity := last.StdDev
+	if last.Mean != 0 {
+		diversity /= math.Abs(last.Mean)
+	}
+	if diversity == 0 {
+		return clampRate(math.Inf(1), dc.Min, dc.Max)
+	}
+	return clampRate(base*target/diversity, dc.Min, dc.Max)
+}
+
+// LinearDecay linearly interpolates the mutation rate from the base rate at generation zero
+// to Final at generation Generations. The rate stays at Final afterwards.
+type LinearDecay struct {
+	// Final is the mutation rate reached after Generations, in range (0, 1].
+	// Zero value is treated as 0.001.
+	Final float64
+	// Generations is the number of generations over which the mutation rate decays.
+	// If not positive the mutation rate is Final from the first generation.
+	Generations int
+}
+
+// MutationRate implements the [MutationController] interface.
+func (ld LinearDecay) MutationRate(base float64, last Stats) float64 {
+	final := clampRate(ld.Final, 0, 0)
+	if ld.Generations <= 0 {
+		return final
+	}
+	t := math.Min(1, float64(last.Generation+1)/float64(ld.Generations))
+	return clampRate(base*(1-t)+final*t, 0, 0)
+}
+
+// ExponentialDecay multiplies the base mutation rate by Factor every generation
+// until it reaches Min.
+type ExponentialDecay struct {
+	// Factor must be in range (0, 1].
+	Factor float64
+	// Min is the lower bound of the mutation rate. Zero value is treated as 0.001.
+	Min float64
+}
+
+// MutationRate implements the [MutationController] interface.
+func (ed ExponentialDecay) MutationRate(base float64, last Stats) float64 {
+	if ed.Factor <= 0 || ed.Factor > 1 {
+		panic("exponential decay factor must be in range (0, 1]")
+	}
+	return clampRate(base*math.Pow(ed.Factor, float64(last.Generation+1)), ed.Min, 1)
+}
+
+// clampRate clamps rate to [min, max] where zero min and max are replaced by defaults.
+func clampRate(rate, min, max float64) float64 {
+	if min == 0 {
+		min = 0.001
+	}
+	if max == 0 {
+		max = 1
+	}
+	return math.Max(min, math.Min(max, rate))
+}
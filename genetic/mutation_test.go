@@ -0,0 +1,76 @@
+package genetic
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestOneFifthRule(t *testing.T) {
+	const base = 0.1
+	var r OneFifthRule
+	rate := r.MutationRate(base, Stats{Evaluations: 10, SuccessRate: 0.5})
+	if rate <= base {
+		t.Errorf("expected rate to increase with high success, got %g", rate)
+	}
+	for i := 0; i < 100; i++ {
+		rate = r.MutationRate(base, Stats{Evaluations: 10, SuccessRate: 0})
+	}
+	if rate != 0.001 {
+		t.Errorf("expected rate to decrease to minimum with no success, got %g", rate)
+	}
+	// Rate should recover quickly after hitting the minimum.
+	rate = r.MutationRate(base, Stats{Evaluations: 10, SuccessRate: 1})
+	if rate <= 0.001 {
+		t.Errorf("expected rate to increase after success, got %g", rate)
+	}
+}
+
+func TestMutationSchedules(t *testing.T) {
+	const base = 0.5
+	linear := LinearDecay{Final: 0.1, Generations: 10}
+	if rate := linear.MutationRate(base, Stats{Generation: 100}); rate != 0.1 {
+		t.Errorf("expected final rate after decay, got %g", rate)
+	}
+	if rate := linear.MutationRate(base, Stats{Generation: 4}); math.Abs(rate-0.3) > 1e-12 {
+		t.Errorf("expected rate halfway through decay, got %g", rate)
+	}
+	// Zero value Final decays to a valid rate.
+	if rate := (LinearDecay{Generations: 10}).MutationRate(base, Stats{Generation: 100}); rate <= 0 {
+		t.Errorf("expected positive rate after decay to zero value Final, got %g", rate)
+	}
+	exp := ExponentialDecay{Factor: 0.5}
+	if rate := exp.MutationRate(base, Stats{Generation: 0}); rate != 0.25 {
+		t.Errorf("expected halved rate, got %g", rate)
+	}
+	div := DiversityControl{Target: 0.1}
+	low := div.MutationRate(base, Stats{Mean: 1, StdDev: 0.01})
+	high := div.MutationRate(base, Stats{Mean: 1, StdDev: 0.5})
+	if low <= high {
+		t.Errorf("expected higher mutation rate for low diversity: %g <= %g", low, high)
+	}
+}
+
+func TestPopulationMutationController(t *testing.T) {
+	ctx := context.Background()
+	pop := newTestPopulation(rand.NewSource(1), 20, 4)
+	pop.SetMutationController(ExponentialDecay{Factor: 0.5})
+	for gen := 0; gen < 3; gen++ {
+		err := pop.Advance(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = pop.Selection(0.8, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := pop.Advance(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rate := pop.Stats().MutationRate; math.Abs(rate-0.1) > 1e-12 {
+		t.Errorf("expected mutation rate 0.1 after three halvings, got %g", rate)
+	}
+}
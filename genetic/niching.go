@@ -0,0 +1,171 @@
+package genetic
+
+import (
+	"math"
+
+	"github.com/soypat/mu8"
+)
+
+// Niching preserves diversity by modifying the selection weights of individuals
+// that crowd the same region of the search space, allowing several peaks
+// of a multimodal problem to be maintained in one Population.
+type Niching interface {
+	// Niche modifies the non-negative selection weights of individuals in place.
+	// distance returns the distance between the ith and jth individuals.
+	Niche(weights []float64, distance func(i, j int) float64)
+}
+
+// Compile-time checks of interface implementation.
+var (
+	_ Niching = FitnessSharing{}
+	_ Niching = Clearing{}
+)
+
+// FitnessSharing divides the selection weight of each individual by its niche count,
+// which is the sum of the sharing function over all individuals in the population.
+// The sharing function is 1-(d/Radius)^Alpha for distances d below Radius and zero otherwise.
+type FitnessSharing struct {
+	// Radius is the niche radius. Individuals further apart than Radius do not share fitness.
+	Radius float64
+	// Alpha controls the shape of the sharing function. Zero value is treated as 1.
+	Alpha float64
+}
+
+// Niche implements the [Niching] interface.
+func (fs FitnessSharing) Niche(weights []float64, distance func(i, j int) float64) {
+	if fs.Radius <= 0 {
+		panic("fitness sharing radius must be positive")
+	}
+	alpha := fs.Alpha
+	if alpha == 0 {
+		alpha = 1
+	}
+	nicheCount := make([]float64, len(weights))
+	for i := range weights {
+		nicheCount[i] += 1 // Sharing with itself.
+		for j := i + 1; j < len(weights); j++ {
+			d := distance(i, j)
+			if d < fs.Radius {
+				sh := 1 - math.Pow(d/fs.Radius, alpha)
+				nicheCount[i] += sh
+				nicheCount[j] += sh
+			}
+		}
+	}
+	for i := range weights {
+		weights[i] /= nicheCount[i]
+	}
+}
+
+// Clearing implements Pétrowski's clearing procedure. Within each niche of
+// the given Radius only the Capacity fittest individuals keep their selection weight,
+// the rest have their weight cleared to zero.
+type Clearing struct {
+	// Radius is the niche radius.
+	Radius float64
+	// Capacity is the number of winners per niche. Zero value is treated as 1.
+	Capacity int
+}
+
+// Niche implements the [Niching] interface.
+func (c Clearing) Niche(weights []float64, distance func(i, j int) float64) {
+	if c.Radius <= 0 {
+		panic("clearing radius must be positive")
+	}
+	capacity := c.Capacity
+	if capacity <= 0 {
+		capacity = 1
+	}
+	ranked := argsort(weights)
+	cleared := make([]bool, len(weights))
+	for k, i := range ranked {
+		if cleared[i] || weights[i] == 0 {
+			continue
+		}
+		winners := 1
+		for _, j := range ranked[k+1:] {
+			if cleared[j] || distance(i, j) >= c.Radius {
+				continue
+			}
+			if winners < capacity {
+				winners++
+			} else {
+				cleared[j] = true
+				weights[j] = 0
+			}
+		}
+	}
+}
+
+// SetDistance sets the function used to measure the distance between two individuals
+// for niching and crowding. If nil, which is the default, [mu8.Distance] is used and all
+// Genes must implement [mu8.GeneDistancer].
+func (pop *Population[G]) SetDistance(distance func(a, b G) float64) { pop.distance = distance }
+
+// SetNiching sets the niching method applied to selection weights after the fitness
+// transform, if any. Passing nil disables niching. Niching requires computing the distance between
+// individuals of the Population. See [Population.SetDistance].
+//
+//	pop.SetNiching(genetic.FitnessSharing{Radius: 0.5})
+func (pop *Population[G]) SetNiching(n Niching) { pop.niching = n }
+
+// Peaks returns the best individual of each niche in the population as found during the last
+// call to Advance, in order of descending fitness. Niches are built greedily: an individual
+// is a peak if it is further than radius from all fitter peaks. This allows recovering
+// several distinct good designs from a single run. The returned individuals
+// are part of the Population and should be cloned before modification.
+func (pop *Population[G]) Peaks(radius float64) ([]G, error) {
+	var peaks []G
+	for _, i := range pop.ranking() {
+		isPeak := true
+		for _, peak := range peaks {
+			d, err := pop.genomeDistance(pop.individuals[i], peak)
+			if err != nil {
+				return nil, err
+			}
+			if d < radius {
+				isPeak = false
+				break
+			}
+		}
+		if isPeak {
+			peaks = append(peaks, pop.individuals[i])
+		}
+	}
+	return peaks, nil
+}
+
+// genomeDistance returns the distance between a and b using the Population's distance function.
+func (pop *Population[G]) genomeDistance(a, b G) (float64, error) {
+	if pop.distance != nil {
+		return pop.distance(a, b), nil
+	}
+	return mu8.Distance(a, b)
+}
+
+// niche applies the Population's niching method to the selection weights.
+func (pop *Population[G]) niche() error {
+	if len(pop.individuals) > 1 {
+		// Check distance can be computed so that the distance callback can't fail.
+		_, err := pop.genomeDistance(pop.individuals[0], pop.individuals[1])
+		if err != nil {
+			return err
+		}
+	}
+	// Memoize distances since niching methods may query the same pair many times.
+	n := len(pop.individuals)
+	memo := make([]float64, n*n)
+	fill(memo, math.NaN())
+	pop.niching.Niche(pop.weights, func(i, j int) float64 {
+		if i > j {
+			i, j = j, i
+		}
+		d := memo[i*n+j]
+		if math.IsNaN(d) {
+			d, _ = pop.genomeDistance(pop.individuals[i], pop.individuals[j])
+			memo[i*n+j] = d
+		}
+		return d
+	})
+	return nil
+}
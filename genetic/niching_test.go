@@ -0,0 +1,133 @@
+package genetic
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestNichingMethods(t *testing.T) {
+	// Two clusters: individuals 0-2 around 0 and individuals 3-4 around 10.
+	positions := []float64{0, 0.1, 0.2, 10, 10.1}
+	distance := func(i, j int) float64 { return math.Abs(positions[i] - positions[j]) }
+
+	weights := []float64{1, 1, 1, 1, 1}
+	FitnessSharing{Radius: 1}.Niche(weights, distance)
+	// Members of the less crowded niche keep more of their weight.
+	if weights[3] <= weights[1] {
+		t.Errorf("fitness sharing did not favor less crowded niche: %v", weights)
+	}
+	for i, w := range weights {
+		if w <= 0 {
+			t.Errorf("fitness sharing weight %d not positive: %g", i, w)
+		}
+	}
+
+	weights = []float64{4, 3, 2, 1, 0.5}
+	Clearing{Radius: 1}.Niche(weights, distance)
+	expect := []float64{4, 0, 0, 1, 0}
+	for i := range weights {
+		if weights[i] != expect[i] {
+			t.Fatalf("clearing: got %v, expected %v", weights, expect)
+		}
+	}
+	weights = []float64{4, 3, 2, 1, 0.5}
+	Clearing{Radius: 1, Capacity: 2}.Niche(weights, distance)
+	expect = []float64{4, 3, 0, 1, 0.5}
+	for i := range weights {
+		if weights[i] != expect[i] {
+			t.Fatalf("clearing with capacity: got %v, expected %v", weights, expect)
+		}
+	}
+}
+
+func TestPopulationNiching(t *testing.T) {
+	const Nindividuals = 20
+	ctx := context.Background()
+	for _, niching := range []Niching{FitnessSharing{Radius: 0.5}, Clearing{Radius: 0.5}} {
+		pop := newTestPopulation(rand.NewSource(1), Nindividuals, 4)
+		pop.SetNiching(niching)
+		for gen := 0; gen < 10; gen++ {
+			err := pop.Advance(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = pop.Selection(0.2, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		err := pop.Advance(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		peaks, err := pop.Peaks(0.5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(peaks) == 0 || peaks[0] != pop.individuals[pop.ranking()[0]] {
+			t.Fatalf("%T: first peak is not the fittest individual", niching)
+		}
+		for i := range peaks {
+			for j := i + 1; j < len(peaks); j++ {
+				d, _ := pop.genomeDistance(peaks[i], peaks[j])
+				if d < 0.5 {
+					t.Fatalf("%T: peaks %d and %d closer than radius: %g", niching, i, j, d)
+				}
+			}
+		}
+	}
+
+	// User supplied distance takes precedence over gene distance.
+	pop := newTestPopulation(rand.NewSource(1), Nindividuals, 4)
+	pop.SetNiching(Clearing{Radius: 1})
+	called := false
+	pop.SetDistance(func(a, b *cfgenome) float64 {
+		called = true
+		return 0
+	})
+	err := pop.Advance(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !called {
+		t.Fatal("distance function not used")
+	}
+	peaks, err := pop.Peaks(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(peaks) != 1 {
+		t.Fatalf("expected a single peak with zero distance, got %d", len(peaks))
+	}
+}
+
+func TestCrowding(t *testing.T) {
+	ctx := context.Background()
+	pop := newTestPopulation(rand.NewSource(1), 21, 4)
+	pop.SetCrowding(true)
+	var prev []float64
+	for gen := 0; gen < 10; gen++ {
+		err := pop.Advance(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Children only replace their parent if they are at least as fit.
+		for i := range prev {
+			if pop.fitness[i] < prev[i] {
+				t.Fatalf("gen %d: fitness of individual %d decreased from %g to %g", gen, i, prev[i], pop.fitness[i])
+			}
+		}
+		for i, ind := range pop.Individuals() {
+			if ind.Simulate(ctx) != pop.fitness[i] {
+				t.Fatalf("gen %d: fitness of individual %d out of sync", gen, i)
+			}
+		}
+		prev = append(prev[:0], pop.fitness...)
+		err = pop.Selection(0.2, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
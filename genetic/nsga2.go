@@ -0,0 +1,343 @@
+package genetic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/soypat/mu8"
+)
+
+var (
+	errObjectiveCount    = errors.New("number of objectives returned by Simulate does not match other individuals or goals")
+	errInvalidObjectives = fmt.Errorf("%w: in objectives returned by MultiGenome", mu8.ErrInvalidFitness)
+)
+
+// NSGA2 implements the Non-dominated Sorting Genetic Algorithm II for multi-objective
+// optimization. Individuals are ranked by Pareto dominance: the first front contains
+// individuals no other individual improves on in every objective, the second front those
+// only dominated by the first front, and so on. Within a front individuals in less crowded
+// regions of the objective space are preferred so that the front is evenly covered.
+//
+// Each generation consists of a call to Advance, which simulates new individuals
+// and keeps the best ones, followed by a call to Selection, which breeds as many children as
+// there are individuals in the population.
+type NSGA2[G mu8.MultiGenome] struct {
+	individuals []G
+	generator   func() G
+	// objectives of each individual. Nil for individuals not yet simulated.
+	objectives [][]float64
+	// rank is the index of the front of each individual, zero being the Pareto front.
+	rank     []int
+	crowding []float64
+	goals    []Goal
+	// size is the number of individuals kept after each call to Advance.
+	size        int
+	gen         int
+	evals       int
+	concurrency int
+	evaluated   bool
+	rng         rand.Rand
+	// dubiousIndividual returned the invalid objectives dubious during the last call to Advance.
+	dubiousIndividual G
+	dubious           []float64
+}
+
+// ParetoSolution is an individual of the Pareto front and its objectives.
+type ParetoSolution[G mu8.MultiGenome] struct {
+	Individual G
+	Objectives []float64
+}
+
+// NewNSGA2 returns a multi-objective genetic algorithm instance. Its arguments are those of [NewPopulation].
+// All objectives are maximized by default. See [NSGA2.SetGoals].
+func NewNSGA2[G mu8.MultiGenome](individuals []G, src rand.Source, newIndividual func() G) NSGA2[G] {
+	if len(individuals) < 2 {
+		panic("need at least 2 individuals")
+	}
+	if individuals[0].Len() == 0 {
+		panic("individuals must have at least one gene for algorithm to work")
+	}
+	return NSGA2[G]{
+		individuals: individuals,
+		generator:   newIndividual,
+		objectives:  make([][]float64, len(individuals)),
+		size:        len(individuals),
+		concurrency: 1,
+		rng:         *rand.New(src),
+	}
+}
+
+// SetGoals sets the optimization direction of each objective. The number of goals must match
+// the number of objectives returned by Simulate. Calling SetGoals with no arguments
+// restores the default of maximizing all objectives.
+//
+//	nsga.SetGoals(genetic.Maximize, genetic.Minimize) // Maximize apogee, minimize propellant mass.
+func (n *NSGA2[G]) SetGoals(goals ...Goal) {
+	for _, g := range goals {
+		if g != Maximize && g != Minimize {
+			panic("invalid goal")
+		}
+	}
+	n.goals = append([]Goal(nil), goals...)
+}
+
+// SetConcurrency sets the number of goroutines that simulate individuals concurrently
+// during Advance. See [Population.SetConcurrency].
+func (n *NSGA2[G]) SetConcurrency(workers int) {
+	if workers <= 0 {
+		panic("concurrency must be greater than 0")
+	}
+	n.concurrency = workers
+}
+
+// Advance simulates individuals bred during the last call to Selection and keeps the best
+// individuals of parents and children according to their non-domination rank and crowding distance.
+func (n *NSGA2[G]) Advance(ctx context.Context) error {
+	var pending []int
+	for i := range n.individuals {
+		if n.objectives[i] == nil {
+			pending = append(pending, i)
+		}
+	}
+	results := make([][]float64, len(pending))
+	done := parallel(ctx, len(pending), n.concurrency, func(k int) bool {
+		// Copy objectives so that individuals may reuse the returned slice.
+		objectives := n.individuals[pending[k]].Simulate(ctx)
+		results[k] = append(make([]float64, 0, len(objectives)), objectives...)
+		return validObjectives(results[k])
+	})
+	// Valid results are committed so that they are not simulated again after an error.
+	invalid := -1
+	for k := 0; k < done; k++ {
+		i := pending[k]
+		switch {
+		case validObjectives(results[k]):
+			n.objectives[i] = results[k]
+			n.evals++
+		case invalid < 0:
+			invalid = i
+			n.dubiousIndividual, n.dubious = n.individuals[i], results[k]
+		}
+	}
+	if invalid >= 0 {
+		return errInvalidObjectives
+	}
+	if done < len(pending) {
+		return ctx.Err()
+	}
+	nobj := len(n.objectives[0])
+	if len(n.goals) != 0 && len(n.goals) != nobj {
+		return errObjectiveCount
+	}
+	for _, obj := range n.objectives {
+		if len(obj) != nobj || nobj == 0 {
+			return errObjectiveCount
+		}
+	}
+
+	n.sort()
+	if len(n.individuals) > n.size {
+		// Survivors are the lowest ranked individuals, least crowded first.
+		order := make([]int, len(n.individuals))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(a, b int) bool { return n.crowdedLess(order[a], order[b]) })
+		individuals := make([]G, n.size)
+		objectives := make([][]float64, n.size)
+		for k, i := range order[:n.size] {
+			individuals[k] = n.individuals[i]
+			objectives[k] = n.objectives[i]
+		}
+		n.individuals, n.objectives = individuals, objectives
+		n.sort()
+	}
+	n.evaluated = true
+	return nil
+}
+
+// Selection breeds as many children as there are individuals in the population. Parents are
+// chosen by binary tournament using non-domination rank and crowding distance.
+// mutationRate and polygamy are those of [Population.Selection].
+func (n *NSGA2[G]) Selection(mutationRate float64, polygamy int) error {
+	switch {
+	case !n.evaluated:
+		return errNotEvaluated
+	case mutationRate <= 0 || mutationRate > 1:
+		return errBadMutationRate
+	case polygamy < 0 || polygamy >= n.size:
+		return errBadPolygamy
+	}
+	children := make([]G, n.size)
+	parents := make([]G, 0, polygamy+1)
+	for k := range children {
+		parents = parents[:0]
+		var chosen []int
+		for len(chosen) < polygamy+1 {
+			idx := n.tournament()
+			if !contains(chosen, idx) {
+				chosen = append(chosen, idx)
+				parents = append(parents, n.individuals[idx])
+			}
+		}
+		child := n.generator()
+		err := splice(&n.rng, child, parents[0], parents[1:])
+		if err != nil {
+			return err
+		}
+		mu8.Mutate(child, &n.rng, mutationRate)
+		children[k] = child
+	}
+	n.individuals = append(n.individuals, children...)
+	n.objectives = append(n.objectives, make([][]float64, len(children))...)
+	n.evaluated = false
+	n.gen++
+	return nil
+}
+
+// Individuals returns the individuals of the population. After a call to Selection
+// these include the children bred.
+func (n *NSGA2[G]) Individuals() []G { return n.individuals }
+
+// Evaluations returns the number of simulations performed by calls to Advance whose
+// objectives were kept. See [Population.Evaluations].
+func (n *NSGA2[G]) Evaluations() int { return n.evals }
+
+// DubiousIndividual returns the individual whose objectives were invalid during the last
+// call to Advance and a copy of the objectives it returned. See [Population.DubiousIndividual].
+func (n *NSGA2[G]) DubiousIndividual() (dubious G, problematicObjectives []float64) {
+	return n.dubiousIndividual, n.dubious
+}
+
+// ParetoFront returns the non-dominated individuals found during the last call to Advance
+// and their objectives. The returned individuals are part of the population and
+// should be cloned before modification.
+func (n *NSGA2[G]) ParetoFront() []ParetoSolution[G] {
+	var front []ParetoSolution[G]
+	for i, r := range n.rank {
+		if r == 0 {
+			front = append(front, ParetoSolution[G]{
+				Individual: n.individuals[i],
+				Objectives: append([]float64(nil), n.objectives[i]...),
+			})
+		}
+	}
+	return front
+}
+
+// tournament returns the winner of a binary tournament between two random individuals.
+func (n *NSGA2[G]) tournament() int {
+	a := n.rng.Intn(len(n.individuals))
+	b := n.rng.Intn(len(n.individuals))
+	if n.crowdedLess(b, a) {
+		return b
+	}
+	return a
+}
+
+// crowdedLess implements the crowded comparison operator: it reports whether
+// individual i is preferred over j.
+func (n *NSGA2[G]) crowdedLess(i, j int) bool {
+	if n.rank[i] != n.rank[j] {
+		return n.rank[i] < n.rank[j]
+	}
+	return n.crowding[i] > n.crowding[j]
+}
+
+// sort computes the non-domination rank and crowding distance of all individuals
+// using the fast non-dominated sort.
+func (n *NSGA2[G]) sort() {
+	N := len(n.individuals)
+	n.rank = make([]int, N)
+	n.crowding = make([]float64, N)
+	dominated := make([][]int, N) // Individuals dominated by i.
+	dominators := make([]int, N)  // Number of individuals that dominate i.
+	var front []int
+	for i := 0; i < N; i++ {
+		for j := i + 1; j < N; j++ {
+			switch {
+			case n.dominates(i, j):
+				dominated[i] = append(dominated[i], j)
+				dominators[j]++
+			case n.dominates(j, i):
+				dominated[j] = append(dominated[j], i)
+				dominators[i]++
+			}
+		}
+		// Pairs (k, i) with k < i were compared in previous iterations so dominators[i] is final.
+		if dominators[i] == 0 {
+			front = append(front, i)
+		}
+	}
+	for rank := 0; len(front) > 0; rank++ {
+		n.crowdingDistance(front)
+		var next []int
+		for _, i := range front {
+			n.rank[i] = rank
+			for _, j := range dominated[i] {
+				dominators[j]--
+				if dominators[j] == 0 {
+					next = append(next, j)
+				}
+			}
+		}
+		front = next
+	}
+}
+
+// crowdingDistance computes the crowding distance of individuals of a front, that is to say
+// the normalized perimeter of the cuboid formed by their nearest neighbors in objective space.
+// Individuals at the boundary of the front have infinite crowding distance.
+func (n *NSGA2[G]) crowdingDistance(front []int) {
+	sorted := append([]int(nil), front...)
+	for _, i := range front {
+		n.crowding[i] = 0
+	}
+	for m := range n.objectives[front[0]] {
+		sort.SliceStable(sorted, func(a, b int) bool {
+			return n.objectives[sorted[a]][m] < n.objectives[sorted[b]][m]
+		})
+		min := n.objectives[sorted[0]][m]
+		max := n.objectives[sorted[len(sorted)-1]][m]
+		n.crowding[sorted[0]] = math.Inf(1)
+		n.crowding[sorted[len(sorted)-1]] = math.Inf(1)
+		if max == min {
+			continue
+		}
+		for k := 1; k < len(sorted)-1; k++ {
+			n.crowding[sorted[k]] += (n.objectives[sorted[k+1]][m] - n.objectives[sorted[k-1]][m]) / (max - min)
+		}
+	}
+}
+
+// dominates reports whether individual i Pareto dominates individual j: i is no worse than j
+// in all objectives and better in at least one.
+func (n *NSGA2[G]) dominates(i, j int) bool {
+	better := false
+	for m, a := range n.objectives[i] {
+		b := n.objectives[j][m]
+		goal := Maximize
+		if len(n.goals) > 0 {
+			goal = n.goals[m]
+		}
+		if goal.better(b, a) {
+			return false
+		} else if goal.better(a, b) {
+			better = true
+		}
+	}
+	return better
+}
+
+func validObjectives(objectives []float64) bool {
+	for _, v := range objectives {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return false
+		}
+	}
+	return true
+}
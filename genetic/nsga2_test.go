@@ -0,0 +1,171 @@
+package genetic
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/soypat/mu8"
+	"github.com/soypat/mu8/genes"
+)
+
+func TestNSGA2Sort(t *testing.T) {
+	n := NSGA2[*mogenome]{
+		individuals: make([]*mogenome, 5),
+		objectives: [][]float64{
+			{1, 4}, // Front 0.
+			{2, 2}, // Front 0.
+			{4, 1}, // Front 0.
+			{1, 1}, // Dominated by 1 and 2.
+			{0, 0}, // Dominated by all.
+		},
+	}
+	n.sort()
+	expect := []int{0, 0, 0, 1, 2}
+	for i := range expect {
+		if n.rank[i] != expect[i] {
+			t.Fatalf("got ranks %v, expected %v", n.rank, expect)
+		}
+	}
+	if !math.IsInf(n.crowding[0], 1) || !math.IsInf(n.crowding[2], 1) || math.IsInf(n.crowding[1], 1) {
+		t.Errorf("bad crowding distances %v", n.crowding)
+	}
+	n.SetGoals(Minimize, Minimize)
+	n.sort()
+	if n.rank[4] != 0 || n.rank[0] == 0 {
+		t.Errorf("bad ranks when minimizing: %v", n.rank)
+	}
+}
+
+func TestNSGA2(t *testing.T) {
+	const (
+		Nindividuals = 40
+		Ngenerations = 40
+	)
+	ctx := context.Background()
+	src := mu8.NewSource(1)
+	individuals := make([]*mogenome, Nindividuals)
+	for i := range individuals {
+		individuals[i] = &mogenome{}
+		mu8.Mutate(individuals[i], src, 1)
+	}
+	nsga := NewNSGA2(individuals, src, func() *mogenome { return &mogenome{} })
+	nsga.SetConcurrency(4)
+	for gen := 0; gen < Ngenerations; gen++ {
+		err := nsga.Advance(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = nsga.Selection(0.2, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := nsga.Advance(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nsga.Individuals()) != Nindividuals {
+		t.Fatalf("expected %d individuals, got %d", Nindividuals, len(nsga.Individuals()))
+	}
+	front := nsga.ParetoFront()
+	if len(front) < Nindividuals/4 {
+		t.Fatalf("expected a spread Pareto front, got %d solutions", len(front))
+	}
+	minX, maxX := math.Inf(1), math.Inf(-1)
+	for _, sol := range front {
+		if y := sol.Individual.genes[1].Value(); y > 0.1 {
+			t.Errorf("Pareto solution far from optimal front: y=%g", y)
+		}
+		x := sol.Objectives[0] + sol.Individual.genes[1].Value()
+		minX = math.Min(minX, x)
+		maxX = math.Max(maxX, x)
+	}
+	if maxX-minX < 0.5 {
+		t.Errorf("Pareto front not spread: x in [%g, %g]", minX, maxX)
+	}
+	if nsga.Evaluations() != Nindividuals*(Ngenerations+1) {
+		t.Errorf("expected %d evaluations, got %d", Nindividuals*(Ngenerations+1), nsga.Evaluations())
+	}
+}
+
+// mogenome has two competing objectives x-y and 1-x-y. Its Pareto front is y=0.
+type mogenome struct {
+	genes [2]genes.ConstrainedFloat
+}
+
+func (g *mogenome) GetGene(i int) mu8.Gene { return &g.genes[i] }
+func (g *mogenome) Len() int               { return len(g.genes) }
+
+func (g *mogenome) Simulate(context.Context) []float64 {
+	x, y := g.genes[0].Value(), g.genes[1].Value()
+	return []float64{x - y, 1 - x - y}
+}
+
+func TestNSGA2AdvanceErrors(t *testing.T) {
+	const Nindividuals = 10
+	src := mu8.NewSource(1)
+	var buf [2]float64 // Shared by all individuals to check objectives are copied.
+	var simulations int
+	ctx, cancel := context.WithCancel(context.Background())
+	individuals := make([]*bufgenome, Nindividuals)
+	for i := range individuals {
+		individuals[i] = &bufgenome{buf: &buf, sims: &simulations}
+		mu8.Mutate(individuals[i], src, 1)
+	}
+	individuals[Nindividuals/2].cancel = cancel
+	nsga := NewNSGA2(individuals, src, func() *bufgenome { return &bufgenome{buf: &buf, sims: &simulations} })
+	err := nsga.Advance(ctx)
+	if err != context.Canceled {
+		t.Fatalf("expected cancellation error, got %v", err)
+	}
+	if nsga.Evaluations() != Nindividuals/2+1 {
+		t.Errorf("expected %d committed evaluations, got %d", Nindividuals/2+1, nsga.Evaluations())
+	}
+	individuals[Nindividuals-1].nan = true
+	err = nsga.Advance(context.Background())
+	if !errors.Is(err, mu8.ErrInvalidFitness) {
+		t.Fatalf("expected invalid objectives error, got %v", err)
+	}
+	dubious, objectives := nsga.DubiousIndividual()
+	if dubious != individuals[Nindividuals-1] || len(objectives) != 2 || !math.IsNaN(objectives[0]) {
+		t.Errorf("bad dubious individual or objectives %v", objectives)
+	}
+	individuals[Nindividuals-1].nan = false
+	err = nsga.Advance(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nsga.Evaluations() != Nindividuals || simulations != Nindividuals+1 {
+		t.Errorf("expected %d evaluations and %d simulations, got %d and %d", Nindividuals, Nindividuals+1, nsga.Evaluations(), simulations)
+	}
+	for i, ind := range individuals {
+		x, y := ind.genes[0].Value(), ind.genes[1].Value()
+		if obj := nsga.objectives[i]; obj[0] != x-y || obj[1] != 1-x-y {
+			t.Fatalf("objectives of individual %d not copied: got %v", i, obj)
+		}
+	}
+}
+
+// bufgenome is a mogenome that returns its objectives in a shared buffer. It returns NaN objectives
+// if nan is set and calls cancel once simulated.
+type bufgenome struct {
+	mogenome
+	buf    *[2]float64
+	sims   *int
+	nan    bool
+	cancel context.CancelFunc
+}
+
+func (g *bufgenome) Simulate(ctx context.Context) []float64 {
+	*g.sims++
+	copy(g.buf[:], g.mogenome.Simulate(ctx))
+	if g.nan {
+		g.buf[0] = math.NaN()
+	}
+	if g.cancel != nil {
+		g.cancel()
+	}
+	return g.buf[:]
+}
@@ -6,6 +6,9 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/soypat/mu8"
 )
@@ -15,11 +18,12 @@ var (
 	ErrZeroFitnessSum      = errors.New("zero fitness sum: cannot make decisions")
 	ErrInfFitnessSum       = errors.New("infinite fitness sum: fitnesses returned by individuals are too large")
 	errCodependency        = fmt.Errorf("%w: check for preserved references in newIndividual function. See mu8.FindCodependency", mu8.ErrCodependency)
-	errNegativeFitness     = fmt.Errorf("%w: use zero instead. See pop.DubiousIndividual to recover problematic Genome information", mu8.ErrNegativeFitness)
+	errNegativeFitness     = fmt.Errorf("%w: use zero instead or set a FitnessTransform. See pop.DubiousIndividual to recover problematic Genome information", mu8.ErrNegativeFitness)
 	errInvalidFitness      = fmt.Errorf("%w:  See pop.DubiousIndividual to recover problematic Genome information", mu8.ErrInvalidFitness)
 	errChampionZeroFitness = errors.New("zero fitness champion: consider initializing Population with a non-zero fitness individuals or you may never get results")
 	errBadPolygamy         = errors.New("bad polygamy: must be in range [0, Nindividuals)")
 	errBadMutationRate     = errors.New("bad mutation rate: must be in range (0, 1]")
+	errBadElitism          = errors.New("bad elitism: must be in range [0, Nindividuals)")
 )
 
 // Population provides a generic implementation
@@ -33,10 +37,101 @@ type Population[G mu8.Genome] struct {
 	dubiousIndividual G
 	dubious           float64
 	champFitness      float64
-	fitness           []float64
-	fitnessSum        float64
-	gen               int
-	rng               rand.Rand
+	// hasChamp is set once a champion has been found.
+	hasChamp bool
+	// fitness is the raw fitness returned by Simulate during the last call to Advance.
+	fitness []float64
+	// weights is the transformed fitness used for selection. fitnessSum is the sum of weights.
+	weights    []float64
+	fitnessSum float64
+	goal       Goal
+	transform  FitnessTransform
+	gen        int
+	rng        rand.Rand
+	// src is the source of rng, kept to checkpoint its state.
+	src rand.Source
+	// selector picks parents during Selection. If nil Roulette is used.
+	selector Selector
+	// repeatParents allows an individual to be selected more than once as parent of a child.
+	repeatParents bool
+	// mating restricts which individuals may mate with the first parent of a child.
+	mating MatingRestriction
+	// elitism is the number of fittest individuals carried unchanged into the next generation.
+	elitism int
+	// concurrency is the number of goroutines simulating individuals during Advance.
+	concurrency int
+	// evals counts calls to Simulate.
+	evals int
+	// useCache enables reuse of the fitness of individuals that have
+	// not changed since they were last simulated.
+	useCache bool
+	// cached is true for individuals whose fitness is known and stored in cache.
+	cached []bool
+	cache  []float64
+	// memo maps hashes of individuals simulated in last call to Advance to clones of
+	// those individuals, their fitness and violation. Only used if G implements Hasher.
+	memo map[uint64][]memoized[G]
+	// evaluated is true when fitness corresponds to the current individuals,
+	// that is to say after Advance and before Selection.
+	evaluated bool
+	// replacement is the strategy used to choose which individual is replaced by a child in SteadyState.
+	replacement Replacement
+	// stats of last successful call to Advance or SteadyState.
+	stats Stats
+	// parentFitness is the fitness of the first parent of each individual or NaN
+	// if the individual was not bred during last call to Selection.
+	parentFitness []float64
+	// mutationRate is the last mutation rate used to breed individuals.
+	mutationRate float64
+	controller   MutationController
+	// distance between individuals. If nil mu8.Distance is used.
+	distance func(a, b G) float64
+	niching  Niching
+	// crowding enables deterministic crowding during Selection. rivals holds the parent each
+	// child competes with until the next call to Advance. Nil if Selection did not crowd.
+	crowding bool
+	rivals   []rival[G]
+	// violation is the constraint violation of each individual. Nil if G does not implement
+	// mu8.Constrained. violationCache holds the violation of individuals whose fitness is cached.
+	violation      []float64
+	violationCache []float64
+	constraints    ConstraintHandler
+	// infeasibleChamp is the best individual found that violates constraints.
+	infeasibleChamp     G
+	infeasibleFitness   float64
+	infeasibleViolation float64
+	hasInfeasibleChamp  bool
+	// failure is the policy applied when evaluation of an individual fails.
+	failure FailurePolicy
+	// evaluator computes the fitness of individuals. If nil Simulate is used.
+	evaluator  Evaluator[G]
+	hallOfFame *HallOfFame[G]
+	duplicates Duplicates
+}
+
+// Evaluator computes the fitness of an individual. It allows evaluating a Genome type under
+// different scenarios or objectives without defining wrapper types. The returned fitness has
+// the same meaning as that returned by the Genome's Simulate method, which is not called.
+type Evaluator[G mu8.Genome] func(ctx context.Context, individual G) (fitness float64)
+
+// FailurePolicy determines how a Population handles failed evaluations of Genomes that
+// implement [mu8.Evaluator]. The zero value aborts on the first failure.
+type FailurePolicy struct {
+	// Retries is the number of times a failed evaluation is retried before
+	// the failure is handled. Retrying only makes sense for non-deterministic failures.
+	Retries int
+	// ZeroFitness assigns zero fitness to individuals whose evaluation failed after all retries
+	// instead of aborting. The failed individual is then unlikely to be selected when maximizing.
+	ZeroFitness bool
+}
+
+// Hasher is implemented by Genomes that can report a hash of their genetic content.
+// Genomes with identical Genes must return the same hash and Genomes with different
+// Genes should return different hashes. Individuals with equal hashes are compared with
+// [mu8.Equal] if their Genes implement [mu8.GeneHasher]. Otherwise hash collisions
+// result in an individual being assigned the fitness of another individual.
+type Hasher interface {
+	Hash() uint64
 }
 
 // NewPopulation should be called when instantiating a new
@@ -53,6 +148,7 @@ type Population[G mu8.Genome] struct {
 // for consistent results. It is not recommended for one to use a crypto/rand source directly.
 // If a true random run is required then it is strongly suggested rand.NewSource(trueRandomSeed)
 // is used and the seed saved in between runs to be able to replicate bugs.
+// Use mu8.NewSource to be able to checkpoint the Population with Save.
 //
 // Example:
 //
@@ -66,15 +162,152 @@ func NewPopulation[G mu8.Genome](individuals []G, src rand.Source, newIndividual
 	if individuals[0].Len() == 0 {
 		panic("individuals must have at least one gene for algorithm to work")
 	}
+	var violation, violationCache []float64
+	if _, constrained := any(individuals[0]).(mu8.Constrained); constrained {
+		violation = make([]float64, len(individuals))
+		violationCache = make([]float64, len(individuals))
+	}
 	return Population[G]{
-		individuals: individuals,
-		rng:         *rand.New(src),
-		fitness:     make([]float64, len(individuals)),
-		generator:   newIndividual,
-		champ:       newIndividual(),
+		individuals:    individuals,
+		rng:            *rand.New(src),
+		src:            src,
+		fitness:        make([]float64, len(individuals)),
+		weights:        make([]float64, len(individuals)),
+		generator:      newIndividual,
+		champ:          newIndividual(),
+		elitism:        1,
+		concurrency:    1,
+		cached:         make([]bool, len(individuals)),
+		cache:          make([]float64, len(individuals)),
+		violation:      violation,
+		violationCache: violationCache,
+		// Initial individuals have no parents.
+		parentFitness: slicemap(len(individuals), func(int) float64 { return math.NaN() }),
+	}
+}
+
+// SetSelector sets the parent selection strategy used during Selection.
+// Passing a nil Selector restores the default fitness-proportional [Roulette] selection.
+//
+//	pop.SetSelector(genetic.Tournament{Size: 3})
+func (pop *Population[G]) SetSelector(s Selector) { pop.selector = s }
+
+// SelectionWith performs Selection choosing parents with the Selector s for this call only,
+// which allows varying the selective pressure between generations. The Population's
+// Selector set by SetSelector is left unchanged. Passing a nil Selector uses the Population's Selector.
+//
+//	// Increase selective pressure as the run progresses.
+//	err := pop.SelectionWith(genetic.Tournament{Size: 2 + gen/10}, 0.1, 1)
+func (pop *Population[G]) SelectionWith(s Selector, mutationRate float64, polygamy int) error {
+	if s != nil {
+		prev := pop.selector
+		pop.selector = s
+		defer func() { pop.selector = prev }()
+	}
+	return pop.Selection(mutationRate, polygamy)
+}
+
+// SetElitism sets the number of fittest individuals from the last call to Advance
+// that are cloned unchanged into the next generation during Selection. The fittest
+// individual is placed at index 0 of the new generation, followed by the rest of the elite
+// in order of descending fitness. The default elitism is 1, which keeps only the champion.
+//
+// An elitism of 0 results in a pure generational algorithm. In that case
+// the Population's Champion is the best individual found over all generations.
+func (pop *Population[G]) SetElitism(k int) {
+	if k < 0 {
+		panic("elitism must be non-negative")
+	}
+	pop.elitism = k
+}
+
+// SetConcurrency sets the number of goroutines that simulate individuals concurrently
+// during Advance. The default is 1, which simulates individuals one at a time.
+// Simulate must be safe for concurrent use on distinct individuals when n is greater than 1.
+//
+// Results do not depend on the concurrency: fitnesses are processed in the order of the individuals
+// so that the champion, dubious individual and returned error are the same as those of
+// a sequential run for a given seed.
+func (pop *Population[G]) SetConcurrency(n int) {
+	if n <= 0 {
+		panic("concurrency must be greater than 0")
+	}
+	pop.concurrency = n
+}
+
+// SetFailurePolicy sets how failed evaluations are handled for Genomes that implement
+// [mu8.Evaluator]. By default Advance and SteadyState abort on the first failure and return
+// a *mu8.EvaluationError wrapping the error returned by Evaluate. The failed individual is also available
+// through DubiousIndividual.
+//
+//	pop.SetFailurePolicy(genetic.FailurePolicy{Retries: 2, ZeroFitness: true})
+func (pop *Population[G]) SetFailurePolicy(p FailurePolicy) {
+	if p.Retries < 0 {
+		panic("retries must be non-negative")
 	}
+	pop.failure = p
 }
 
+// SetEvaluator sets the function used to compute the fitness of individuals instead of their
+// Simulate method. Passing nil, which is the default, restores the use of Simulate or Evaluate if the
+// Genome implements [mu8.Evaluator]. Genomes that implement [mu8.Constrained] should
+// update their violation in the evaluator. Setting an evaluator clears the fitness cache.
+//
+//	pop.SetEvaluator(func(ctx context.Context, g *rocket) float64 {
+//		return g.Apogee(ctx, marsGravity)
+//	})
+func (pop *Population[G]) SetEvaluator(f Evaluator[G]) {
+	pop.evaluator = f
+	pop.SetFitnessCache(pop.useCache)
+}
+
+// SetFitnessCache enables or disables fitness caching. When enabled, Advance does not simulate
+// individuals that are unchanged since they were last simulated, such as the elite
+// and children cloned from a parent without crossover or mutation. The last known fitness is
+// used instead. If G implements [Hasher] individuals whose hash matches that of an individual
+// simulated in the last call to Advance are not simulated either.
+//
+// Fitness caching should only be enabled if Simulate is deterministic, that is to say
+// it returns the same fitness every time it is called on the same Genome.
+// Individuals modified outside of the Population's methods invalidate the cache.
+func (pop *Population[G]) SetFitnessCache(enable bool) {
+	pop.useCache = enable
+	for i := range pop.cached {
+		pop.cached[i] = false
+	}
+	pop.memo = nil
+}
+
+// Evaluations returns the number of times individuals have been simulated by calls
+// to Advance and SteadyState. Individuals whose fitness is cached are not counted.
+// The simulations of an NSGA2 are counted by [NSGA2.Evaluations].
+func (pop *Population[G]) Evaluations() int { return pop.evals }
+
+// SetGoal sets the optimization direction of the Population. The default is to
+// maximize fitness. Populations that minimize accept negative fitness and use
+// the Windowing transform unless a FitnessTransform is set.
+func (pop *Population[G]) SetGoal(goal Goal) {
+	if goal != Maximize && goal != Minimize {
+		panic("invalid goal")
+	}
+	pop.goal = goal
+}
+
+// SetFitnessTransform sets the transform applied to fitness before selection.
+// Populations with a FitnessTransform accept negative fitness. Passing nil
+// restores the default behavior: fitness is used as is to select parents when maximizing
+// and negative fitness is an error.
+//
+//	pop.SetFitnessTransform(genetic.SigmaTruncation{C: 2})
+func (pop *Population[G]) SetFitnessTransform(t FitnessTransform) { pop.transform = t }
+
+// SetMutationController sets a controller which adapts the mutation rate between generations.
+// The mutation rate passed to Selection and SteadyState is used as the controller's base rate.
+// Passing nil disables mutation rate control.
+//
+//	pop.SetMutationController(&genetic.OneFifthRule{})
+func (pop *Population[G]) SetMutationController(c MutationController) { pop.controller = c }
+
 // Individuals returns a reference the pool of individuals participating in
 // the simulation. Calling Selection will update the value returned by
 // Individuals if not cloned before calling Selection.
@@ -83,66 +316,449 @@ func (pop *Population[G]) Individuals() []G { return pop.individuals }
 // Advance simulates current population and saves fitness scores. Multiple
 // calls to Advance without calling Selection may have undesired effects.
 func (pop *Population[G]) Advance(ctx context.Context) error {
+	start := time.Now()
+	startEvals := pop.evals
 	pop.fitnessSum = 0
-	maxFitness := math.Inf(-1)
 	champIdx := -1
-	fitnessSum := 0.0
-	for i := 0; i < len(pop.individuals) && ctx.Err() == nil; i++ {
-		fitness := pop.individuals[i].Simulate(ctx)
+	// zeroFilled marks individuals assigned zero fitness after a failed evaluation.
+	zeroFilled := make([]bool, len(pop.individuals))
+	n, evalErr := pop.simulateUncached(ctx, zeroFilled)
+	for i := 0; i < n; i++ {
+		fitness := pop.fitness[i]
 		// We now check for errors that impede the continuation of the algorithm.
-		if fitness < 0 {
+		if math.IsInf(fitness, 0) || math.IsNaN(fitness) {
 			pop.dubious = fitness
 			pop.dubiousIndividual = pop.individuals[i]
-			return errNegativeFitness
-		} else if math.IsInf(fitness, 0) || math.IsNaN(fitness) {
+			return errInvalidFitness
+		} else if !pop.validFitness(fitness) {
 			pop.dubious = fitness
 			pop.dubiousIndividual = pop.individuals[i]
-			return errInvalidFitness
+			return errNegativeFitness
+		} else if !pop.validViolation(i) {
+			pop.dubious = pop.violation[i]
+			pop.dubiousIndividual = pop.individuals[i]
+			return errInvalidViolation
+		}
+		if !pop.feasible(i) {
+			continue // Infeasible individuals can't be champion.
 		}
-		fitnessSum += fitness
-		pop.fitness[i] = fitness
-		if fitness > maxFitness {
-			maxFitness = fitness
+		if champIdx < 0 || pop.goal.better(fitness, pop.fitness[champIdx]) {
 			champIdx = i
-			if fitness > pop.champFitness {
+			if !pop.hasChamp || pop.goal.better(fitness, pop.champFitness) {
 				// we perform a greedy save of the new possible champion in case context is cancelled before Advance finishes.
 				pop.champ = pop.individuals[i]
 				pop.champFitness = fitness
+				pop.hasChamp = true
 			}
 		}
 	}
+	if evalErr != nil {
+		return evalErr
+	} else if n < len(pop.individuals) && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	crowded := pop.restoreRivals()
+	// An elite, such as the champion, whose evaluation failed loses its fitness legitimately.
+	failedElite := false
+	for i := 0; i < pop.elitism && i < len(zeroFilled); i++ {
+		failedElite = failedElite || zeroFilled[i]
+	}
+	fitnessSum, err := pop.computeWeights(true)
+	if err != nil {
+		return err
+	}
 	switch {
-	case champIdx < 0 || fitnessSum == 0:
+	case (champIdx < 0 && pop.violation == nil) || fitnessSum == 0:
 		return ErrZeroFitnessSum // No decision can be taken and no progress can be made.
-	case pop.fitness[champIdx] < pop.champFitness:
+	case !crowded && !failedElite && pop.elitism > 0 && champIdx >= 0 && pop.goal.better(pop.champFitness, pop.fitness[champIdx]):
 		// This is a big error. It means new instances of individuals are
 		// affected by previous instances Simulation call or calls to gene's Mutate.
 		// If this panic triggers consider all champion data has been compromised
 		// and may not accurately represent "optimal" Genome.
 		panic(errCodependency)
-	case math.IsInf(pop.fitnessSum, 0):
+	case math.IsInf(fitnessSum, 0):
 		return ErrInfFitnessSum
 	}
+	if pop.useCache {
+		err = pop.updateCache()
+		if err != nil {
+			return err
+		}
+	}
+	pop.evaluated = true
+	pop.fitnessSum = fitnessSum
+	offspring, improved := pop.successes()
+	pop.updateStats(start, startEvals, offspring, improved)
+	fill(pop.parentFitness, math.NaN()) // Offspring success has been accounted for.
+	ranked := pop.ranking()
+	err = pop.updateChampions(ranked)
+	if err != nil {
+		return err
+	}
+	return pop.updateHallOfFame(ranked)
+}
+
+// updateStats computes the Population's Stats after a successful call to Advance or SteadyState.
+// offspring and improved are the number of children simulated and how many of them were fitter than their parent.
+func (pop *Population[G]) updateStats(start time.Time, startEvals, offspring, improved int) {
+	pop.stats = fitnessStats(pop.fitness)
+	pop.stats.Generation = pop.gen
+	pop.stats.Evaluations = pop.evals - startEvals
+	pop.stats.WallTime = time.Since(start)
+	pop.stats.MutationRate = pop.mutationRate
+	if offspring > 0 {
+		pop.stats.SuccessRate = float64(improved) / float64(offspring)
+	}
+}
+
+// successes returns the number of individuals bred during last Selection and
+// how many of them are fitter than their first parent.
+func (pop *Population[G]) successes() (offspring, improved int) {
+	for i, parent := range pop.parentFitness {
+		if math.IsNaN(parent) {
+			continue
+		}
+		offspring++
+		if pop.goal.better(pop.fitness[i], parent) {
+			improved++
+		}
+	}
+	return offspring, improved
+}
+
+// nextMutationRate returns the mutation rate used to breed the next generation.
+func (pop *Population[G]) nextMutationRate(base float64) (float64, error) {
+	rate := base
+	if pop.controller != nil {
+		rate = math.Min(1, pop.controller.MutationRate(base, pop.stats))
+		if !(rate > 0) {
+			return 0, errBadMutationRate
+		}
+	}
+	pop.mutationRate = rate
+	return rate, nil
+}
+
+// updateChampion replaces the champion with a clone of the individual at index i if
+// its fitness is not worse than the champion's.
+func (pop *Population[G]) updateChampion(i int) error {
+	if pop.hasChamp && pop.goal.better(pop.champFitness, pop.fitness[i]) {
+		return nil
+	}
 	newChamp := pop.generator()
 	// Clone the champion so that his legacy may live on, untarnished by interbreeding and mutations.
-	err := mu8.Clone(newChamp, pop.individuals[champIdx])
+	err := mu8.Clone(newChamp, pop.individuals[i])
 	if err != nil {
 		return err // TODO: Should this panic?
 	}
 	// Looks like all went down OK. We can now update the population's champion.
 	pop.champ = newChamp
-	pop.fitnessSum = fitnessSum
-	pop.champ = pop.individuals[champIdx]
-	pop.champFitness = pop.fitness[champIdx]
+	pop.champFitness = pop.fitness[i]
+	pop.hasChamp = true
+	return nil
+}
+
+// computeWeights stores the selection weights of individuals in pop.weights
+// and returns their sum. adapt is set at the end of a generation so that
+// adaptive constraint handlers update their state.
+func (pop *Population[G]) computeWeights(adapt bool) (sum float64, err error) {
+	if pop.goal == Maximize && pop.transform == nil && pop.violation == nil {
+		copy(pop.weights, pop.fitness)
+	} else {
+		transform := pop.transform
+		if transform == nil {
+			transform = Windowing{}
+		}
+		adjusted := make([]float64, len(pop.fitness))
+		for i, f := range pop.fitness {
+			if pop.goal == Minimize {
+				f = -f
+			}
+			adjusted[i] = f
+		}
+		if pop.violation != nil {
+			handler := pop.constraintHandler()
+			if a, ok := handler.(adaptiveHandler); ok && adapt {
+				a.adapt(pop.violation)
+			}
+			handler.Handle(&pop.rng, adjusted, adjusted, pop.violation)
+		}
+		transform.Transform(pop.weights, adjusted)
+	}
+	if pop.niching != nil {
+		err = pop.niche()
+		if err != nil {
+			return 0, err
+		}
+	}
+	return sumOf(pop.weights), nil
+}
+
+// ranking returns the indices of individuals sorted from best to worst fitness
+// as found during the last call to Advance. Feasible individuals rank first.
+func (pop *Population[G]) ranking() []int {
+	if pop.violation != nil {
+		return pop.feasibilityRanking(pop.fitness)
+	}
+	if pop.goal == Minimize {
+		negated := make([]float64, len(pop.fitness))
+		for i, f := range pop.fitness {
+			negated[i] = -f
+		}
+		return argsort(negated)
+	}
+	return argsort(pop.fitness)
+}
+
+// simulateUncached stores the fitness of all individuals in pop.fitness, simulating those
+// whose fitness is not cached. It returns the number of leading individuals
+// whose fitness is known and the evaluation error of the next individual, if any. See simulate.
+func (pop *Population[G]) simulateUncached(ctx context.Context, zeroFilled []bool) (n int, err error) {
+	if !pop.useCache {
+		n, err = pop.simulate(ctx, pop.individuals, pop.fitness, pop.violation, zeroFilled)
+		pop.evals += n
+		return n, err
+	}
+	_, hashable := any(pop.individuals[0]).(Hasher)
+	var pending []int
+	for i := range pop.individuals {
+		if pop.cached[i] {
+			pop.fitness[i] = pop.cache[i]
+			if pop.violation != nil {
+				pop.violation[i] = pop.violationCache[i]
+			}
+			continue
+		} else if hashable {
+			if m, ok := lookupMemo(pop.memo, pop.individuals[i]); ok {
+				pop.fitness[i] = m.fitness
+				if pop.violation != nil {
+					pop.violation[i] = m.violation
+				}
+				continue
+			}
+		}
+		pending = append(pending, i)
+	}
+	individuals := make([]G, len(pending))
+	fitness := make([]float64, len(pending))
+	var violation []float64
+	if pop.violation != nil {
+		violation = make([]float64, len(pending))
+	}
+	for k, i := range pending {
+		individuals[k] = pop.individuals[i]
+	}
+	failed := make([]bool, len(pending))
+	simulated, err := pop.simulate(ctx, individuals, fitness, violation, failed)
+	pop.evals += simulated
+	for k := 0; k < simulated; k++ {
+		pop.fitness[pending[k]] = fitness[k]
+		zeroFilled[pending[k]] = failed[k]
+		if violation != nil {
+			pop.violation[pending[k]] = violation[k]
+		}
+	}
+	if simulated < len(pending) {
+		return pending[simulated], err
+	}
+	return len(pop.individuals), nil
+}
+
+// updateCache marks the fitness of all individuals as known after a successful
+// call to Advance and stores clones of them by hash for lookup in the next call to Advance.
+func (pop *Population[G]) updateCache() error {
+	copy(pop.cache, pop.fitness)
+	copy(pop.violationCache, pop.violation)
+	for i := range pop.cached {
+		pop.cached[i] = true
+	}
+	if _, hashable := any(pop.individuals[0]).(Hasher); !hashable {
+		return nil
+	}
+	memo := make(map[uint64][]memoized[G], len(pop.individuals))
+	for i, ind := range pop.individuals {
+		if _, ok := lookupMemo(memo, ind); ok {
+			continue
+		}
+		// Clones are kept since individuals may be modified once replaced.
+		clone := pop.generator()
+		err := mu8.Clone(clone, ind)
+		if err != nil {
+			return err
+		}
+		h := any(ind).(Hasher).Hash()
+		memo[h] = append(memo[h], memoized[G]{ind: clone, fitness: pop.fitness[i], violation: pop.violationOf(i)})
+	}
+	pop.memo = memo
 	return nil
 }
 
+// memoized is a clone of an individual simulated during the last call to Advance,
+// its fitness and constraint violation.
+type memoized[G mu8.Genome] struct {
+	ind       G
+	fitness   float64
+	violation float64
+}
+
+// lookupMemo returns the memoized individual with the same genetic content as g, which must implement Hasher.
+func lookupMemo[G mu8.Genome](memo map[uint64][]memoized[G], g G) (memoized[G], bool) {
+	if memo == nil {
+		return memoized[G]{}, false
+	}
+	for _, m := range memo[any(g).(Hasher).Hash()] {
+		// Hash collisions are told apart if Genes can be compared, otherwise equal hashes are trusted.
+		equal, err := mu8.Equal(g, m.ind)
+		if err != nil || equal {
+			return m, true
+		}
+	}
+	return memoized[G]{}, false
+}
+
+// simulate runs the simulation of individuals and stores the results in fitness.
+// If violation is not nil the constraint violation of each individual is stored in it.
+// If zeroFilled is not nil individuals assigned zero fitness by the FailurePolicy are marked in it.
+// It returns the number of leading individuals that were simulated, which is less than
+// len(individuals) if ctx is cancelled, an invalid fitness was encountered or an evaluation failed,
+// in which case err is a *mu8.EvaluationError of the individual at index n.
+// Simulations run on pop.concurrency goroutines.
+func (pop *Population[G]) simulate(ctx context.Context, individuals []G, fitness, violation []float64, zeroFilled []bool) (n int, err error) {
+	errs := make([]error, len(individuals))
+	n = parallel(ctx, len(individuals), pop.concurrency, func(i int) bool {
+		var failed bool
+		fitness[i], failed, errs[i] = pop.evaluate(ctx, individuals[i])
+		if errs[i] != nil {
+			return false
+		}
+		if zeroFilled != nil {
+			zeroFilled[i] = failed
+		}
+		if violation != nil {
+			violation[i] = any(individuals[i]).(mu8.Constrained).Violation()
+			if !validViolation(violation[i]) {
+				return false
+			}
+		}
+		return pop.validFitness(fitness[i])
+	})
+	if ctx.Err() != nil {
+		return n, nil // Evaluations likely failed due to cancellation.
+	}
+	// Failures are processed in order so that results do not depend on the concurrency.
+	for i, e := range errs[:n] {
+		if e != nil {
+			pop.dubious = math.NaN()
+			pop.dubiousIndividual = individuals[i]
+			return i, &mu8.EvaluationError{Individual: individuals[i], Err: e}
+		}
+	}
+	return n, nil
+}
+
+// evaluate returns the fitness of individual using the Population's Evaluator if set. Otherwise it
+// calls Evaluate if individual implements mu8.Evaluator and applies the Population's FailurePolicy.
+// zeroFilled reports whether the evaluation failed and zero fitness was assigned instead.
+func (pop *Population[G]) evaluate(ctx context.Context, individual G) (fitness float64, zeroFilled bool, err error) {
+	if pop.evaluator != nil {
+		return pop.evaluator(ctx, individual), false, nil
+	}
+	evaluator, ok := any(individual).(mu8.Evaluator)
+	if !ok {
+		return individual.Simulate(ctx), false, nil
+	}
+	for attempt := 0; attempt <= pop.failure.Retries; attempt++ {
+		fitness, err = evaluator.Evaluate(ctx)
+		if err == nil || ctx.Err() != nil {
+			return fitness, false, err
+		}
+	}
+	if pop.failure.ZeroFitness {
+		return 0, true, nil
+	}
+	return fitness, false, err
+}
+
+// parallel calls f for indices 0 to n-1 using up to `workers` goroutines. Calls stop once
+// ctx is cancelled or f returns false. It returns the length of the prefix of
+// indices for which f was called. Panics in f are propagated to the caller.
+func parallel(ctx context.Context, n, workers int, f func(i int) (ok bool)) (done int) {
+	if workers > n {
+		workers = n
+	}
+	if workers <= 1 {
+		for done < n && ctx.Err() == nil {
+			done++
+			if !f(done - 1) {
+				break
+			}
+		}
+		return done
+	}
+
+	var (
+		wg   sync.WaitGroup
+		next int64 = -1
+		stop int32
+		// called marks indices for which f returned. Indices are handed out in order
+		// so called is true for a prefix of indices once all workers return.
+		called   = make([]bool, n)
+		panicMu  sync.Mutex
+		panicVal interface{}
+	)
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer func() {
+				if a := recover(); a != nil {
+					panicMu.Lock()
+					panicVal = a
+					panicMu.Unlock()
+					atomic.StoreInt32(&stop, 1)
+				}
+				wg.Done()
+			}()
+			for ctx.Err() == nil && atomic.LoadInt32(&stop) == 0 {
+				i := int(atomic.AddInt64(&next, 1))
+				if i >= n {
+					return
+				}
+				ok := f(i)
+				called[i] = true
+				if !ok {
+					atomic.StoreInt32(&stop, 1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if panicVal != nil {
+		// Propagate simulation panics to the caller as would happen during a sequential run.
+		panic(panicVal)
+	}
+	for done < n && called[done] {
+		done++
+	}
+	return done
+}
+
+// validFitness reports whether fitness can be used by the genetic algorithm.
+// Negative fitness is only valid if it can be transformed into a selection weight.
+func (pop *Population[G]) validFitness(fitness float64) bool {
+	if math.IsInf(fitness, 0) || math.IsNaN(fitness) {
+		return false
+	}
+	return fitness >= 0 || pop.goal == Minimize || pop.transform != nil || pop.violation != nil
+}
+
 // Selection performs natural selection of individuals in the population.
 // It first breeds individuals (fittest are most likely to be bred) and then
 // mutates the babies obtained from the breeding procedure. The Individuals
-// are updated once this function terminates.
+// are updated once this function terminates. Advance must be called before each call to Selection.
 func (pop *Population[G]) Selection(mutationRate float64, polygamy int) error {
 	switch {
+	case !pop.evaluated:
+		return errNotEvaluated
 	case pop.champFitness == 0 && pop.fitnessSum == 0:
 		// If you are getting this error try redesigning your fitness function
 		// so that it yields a non-zero fitness value for at least one individual.
@@ -156,41 +772,107 @@ func (pop *Population[G]) Selection(mutationRate float64, polygamy int) error {
 		return errBadMutationRate
 	case polygamy < 0 || polygamy > len(pop.individuals):
 		return errBadPolygamy
+	case pop.elitism >= len(pop.individuals):
+		return errBadElitism
+	}
+	mutationRate, err := pop.nextMutationRate(mutationRate)
+	if err != nil {
+		return err
+	}
+	if pop.crowding {
+		return pop.crowdingSelection(mutationRate)
 	}
 
 	newGeneration := make([]G, len(pop.individuals))
-	// Skip first index, reserved for our champion.
-	for i := 1; i < len(pop.individuals); i++ {
-		// Find the meanest, greenest individuals
-		parents := pop.selectFittest(polygamy + 1)
-		child, err := pop.breed(parents[0], parents...)
+	// Fitness of unchanged individuals in the new generation.
+	newCached := make([]bool, len(pop.individuals))
+	newCache := make([]float64, len(pop.individuals))
+	newViolationCache := make([]float64, len(pop.violationCache))
+	// Elite are not bred and have no parent fitness.
+	newParentFitness := slicemap(len(pop.individuals), func(int) float64 { return math.NaN() })
+	eliteIdx := pop.ranking()[:pop.elitism]
+	seen, err := pop.newGenomeSet(eliteIdx)
+	if err != nil {
+		return err
+	}
+	// Skip first indices, reserved for our elite.
+	for i := pop.elitism; i < len(pop.individuals); i++ {
+		child, parents, isClone, err := pop.uniqueOffspring(seen, mutationRate, polygamy)
 		if err != nil {
 			return err
 		}
-		mu8.Mutate(child, &pop.rng, mutationRate)
+		parent := parents[0]
 		newGeneration[i] = child
+		newParentFitness[i] = pop.fitness[parent]
+		if isClone {
+			newCached[i] = pop.cached[parent]
+			newCache[i] = pop.cache[parent]
+			if pop.violation != nil {
+				newViolationCache[i] = pop.violationCache[parent]
+			}
+		}
+	}
+	// Looking out for our elite, champ first.
+	for i, idx := range eliteIdx {
+		elite := pop.generator()
+		err := mu8.Clone(elite, pop.individuals[idx])
+		if err != nil {
+			return err
+		}
+		newGeneration[i] = elite
+		newCached[i] = pop.cached[idx]
+		newCache[i] = pop.cache[idx]
+		if pop.violation != nil {
+			newViolationCache[i] = pop.violationCache[idx]
+		}
 	}
-	// Looking out for our one and only, Champ.
-	newGeneration[0] = pop.champ
 	pop.individuals = newGeneration
+	pop.cached = newCached
+	pop.cache = newCache
+	if pop.violation != nil {
+		pop.violationCache = newViolationCache
+	}
+	pop.parentFitness = newParentFitness
+	pop.evaluated = false
 	pop.gen++
 	return nil
 }
 
+// offspring breeds a child from parents chosen by the Selector and mutates it.
+// It returns the child, the indices of its parents, first parent first, and whether the
+// child is an unchanged clone of its first parent.
+func (pop *Population[G]) offspring(mutationRate float64, polygamy int) (child G, parents []int, isClone bool, err error) {
+	// Find the meanest, greenest individuals
+	parents, err = pop.selectParents(polygamy + 1)
+	if err != nil {
+		return child, nil, false, err
+	}
+	conjugates := make([]G, len(parents))
+	for k, idx := range parents {
+		conjugates[k] = pop.individuals[idx]
+	}
+	child, err = pop.breed(conjugates[0], conjugates[1:]...)
+	if err != nil {
+		return child, nil, false, err
+	}
+	mutated := mu8.Mutate(child, &pop.rng, mutationRate)
+	return child, parents, len(conjugates) == 1 && mutated == 0, nil
+}
+
 // Champion returns the best candidate of the population, this
-// individual possessing the highest fitness score from last call to Advance().
+// individual possessing the best fitness score from last call to Advance().
 func (pop *Population[G]) Champion() G {
 	return pop.champ
 }
 
-// ChampionFitness returns the highest fitness score of the population found
-// during the last call to Advance().
+// ChampionFitness returns the best fitness score of the population found
+// during the last call to Advance(): the highest when maximizing and the lowest when minimizing.
 func (pop *Population[G]) ChampionFitness() float64 {
 	return pop.champFitness
 }
 
 // DubiousIndividual returns the last individual that caused a NaN or Inf
-// result during simulation to aid with debugging. It returns the
+// result or whose evaluation failed during simulation to aid with debugging. It returns the
 // Genome's zero value and 0 if no dubious individual was encountered.
 // The presence of a dubious individual can mean one of two things:
 //
@@ -205,27 +887,53 @@ func (pop *Population[G]) DubiousIndividual() (dubious G, problematicFitness flo
 	return pop.dubiousIndividual, pop.dubious
 }
 
-// selectFittest selects `sample` individuals from the population and returns a slice
-// containing them. The most fittest are the most likely to be selected.
-func (pop *Population[G]) selectFittest(sample int) (fittest []G) {
-	// Quick return for clone case.
-	if sample == 0 {
-		return nil
+// selectParents selects `sample` individuals for breeding using the Population's Selector
+// and mating restriction and returns their indices. The first index is the first parent.
+// Fewer than `sample` indices may be returned if distinct parents could not be found
+// or if the mating restriction rejected candidates.
+func (pop *Population[G]) selectParents(sample int) ([]int, error) {
+	if pop.mating == nil || sample == 1 {
+		return pop.sampleParents(make([]int, sample), nil), nil
 	}
-	// The lucky few selected will statistically be more likely to be fitter, proportional to their fitness.
-	luckOfTheFit := slicemap(sample, func(int) float64 { return pop.fitnessSum * pop.rng.Float64() })
-	runningSum := 0.0
-	for i := 0; len(fittest) < sample; i++ {
-		runningSum += pop.fitness[i]
-		for _, threshold := range luckOfTheFit {
-			// TODO(soypat): This code has to be overhauled so same parent is not selected
-			// more than once.
-			if runningSum > threshold {
-				fittest = append(fittest, pop.individuals[i])
-			}
+	parents := pop.sampleParents(make([]int, 1), nil)
+	candidates := make([]int, pop.mating.Candidates())
+	for mate := 1; mate < sample; mate++ {
+		mates, err := pop.chooseMate(parents, candidates)
+		if err != nil {
+			return nil, err
 		}
+		parents = append(parents, mates...)
 	}
-	return fittest
+	return parents, nil
+}
+
+// sampleParents fills dst with individuals chosen by the Selector and returns it.
+// Unless repeated parents are allowed, individuals already in dst or exclude are redrawn
+// a bounded number of times after which they are dropped from the returned slice.
+func (pop *Population[G]) sampleParents(dst, exclude []int) []int {
+	selector := pop.selector
+	if selector == nil {
+		selector = Roulette{}
+	}
+	selector.Select(&pop.rng, dst, pop.weights)
+	if pop.repeatParents {
+		return dst
+	}
+	const maxRedraws = 8
+	var redraw [1]int
+	n := 0
+	repeated := func(idx int) bool { return contains(dst[:n], idx) || contains(exclude, idx) }
+	for _, idx := range dst {
+		for attempt := 0; attempt < maxRedraws && repeated(idx); attempt++ {
+			selector.Select(&pop.rng, redraw[:], pop.weights)
+			idx = redraw[0]
+		}
+		if !repeated(idx) {
+			dst[n] = idx
+			n++
+		}
+	}
+	return dst[:n]
 }
 
 // breed breeds receiver Genome with other genomes by splicing.
@@ -233,21 +941,32 @@ func (pop *Population[G]) selectFittest(sample int) (fittest []G) {
 // which could be described as a cloning procedure.
 func (pop *Population[G]) breed(firstParent G, conjugates ...G) (G, error) {
 	child := pop.generator()
+	err := splice(&pop.rng, child, firstParent, conjugates)
+	return child, err
+}
+
+// splice clones firstParent into child and splices child's Genes with the conjugates' Genes.
+func splice[G mu8.Genotype](rng *rand.Rand, child, firstParent G, conjugates []G) error {
 	err := mu8.Clone(child, firstParent)
 	if err != nil {
-		return child, err
-	}
-	if len(conjugates) == 0 {
-		return child, nil
+		return err
 	}
-
 	for i := 0; i < child.Len(); i++ {
 		gene := child.GetGene(i)
 		for _, c := range conjugates {
-			gene.Splice(&pop.rng, c.GetGene(i))
+			gene.Splice(rng, c.GetGene(i))
+		}
+	}
+	return nil
+}
+
+func contains(s []int, v int) bool {
+	for _, e := range s {
+		if e == v {
+			return true
 		}
 	}
-	return child, nil
+	return false
 }
 
 func slicemap(n int, f func(int) float64) []float64 {
@@ -0,0 +1,461 @@
+package genetic
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/soypat/mu8"
+)
+
+func newTestPopulation(src rand.Source, Nindividuals, genomelen int) Population[*cfgenome] {
+	individuals := make([]*cfgenome, Nindividuals)
+	for i := range individuals {
+		genome := newGenome(genomelen)
+		mu8.Mutate(genome, src, .5)
+		individuals[i] = genome
+	}
+	return NewPopulation(individuals, src, func() *cfgenome {
+		return newGenome(genomelen)
+	})
+}
+
+// newTestIslands returns Islands of individuals whose genes have all been mutated.
+func newTestIslands(src rand.Source, Nislands, Nindividuals, genomelen int) Islands[*cfgenome] {
+	individuals := make([]*cfgenome, Nindividuals)
+	for i := range individuals {
+		individuals[i] = newGenome(genomelen)
+		mu8.Mutate(individuals[i], src, 1)
+	}
+	return NewIslands(Nislands, individuals, src, func() *cfgenome {
+		return newGenome(genomelen)
+	})
+}
+
+func TestElitism(t *testing.T) {
+	const (
+		elitism      = 4
+		mutationRate = 0.5
+	)
+	ctx := context.Background()
+	pop := newTestPopulation(rand.NewSource(1), 20, 4)
+	pop.SetElitism(elitism)
+	for gen := 0; gen < 10; gen++ {
+		err := pop.Advance(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ranked := argsort(pop.fitness)
+		var expect []float64
+		for _, idx := range ranked[:elitism] {
+			expect = append(expect, pop.fitness[idx])
+		}
+		err = pop.Selection(mutationRate, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < elitism; i++ {
+			got := pop.Individuals()[i].Simulate(ctx)
+			if got != expect[i] {
+				t.Fatalf("gen %d: elite %d fitness %g, expected %g", gen, i, got, expect[i])
+			}
+		}
+	}
+}
+
+func TestNoElitism(t *testing.T) {
+	ctx := context.Background()
+	pop := newTestPopulation(rand.NewSource(1), 20, 4)
+	pop.SetElitism(0)
+	best := 0.0
+	for gen := 0; gen < 10; gen++ {
+		err := pop.Advance(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pop.ChampionFitness() < best {
+			t.Fatalf("champion fitness decreased without elitism: %g < %g", pop.ChampionFitness(), best)
+		}
+		best = pop.ChampionFitness()
+		err = pop.Selection(0.5, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestConcurrentAdvance(t *testing.T) {
+	const Ngen = 20
+	ctx := context.Background()
+	sequential := newTestPopulation(rand.NewSource(1), 50, 4)
+	concurrent := newTestPopulation(rand.NewSource(1), 50, 4)
+	concurrent.SetConcurrency(4)
+	for gen := 0; gen < Ngen; gen++ {
+		for _, pop := range []*Population[*cfgenome]{&sequential, &concurrent} {
+			err := pop.Advance(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = pop.Selection(0.2, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		if sequential.ChampionFitness() != concurrent.ChampionFitness() {
+			t.Fatalf("gen %d: sequential and concurrent champion fitness differ: %g != %g",
+				gen, sequential.ChampionFitness(), concurrent.ChampionFitness())
+		}
+	}
+}
+
+func TestFitnessCache(t *testing.T) {
+	const (
+		Nindividuals = 20
+		Ngen         = 10
+		elitism      = 3
+	)
+	ctx := context.Background()
+	uncached := newTestPopulation(rand.NewSource(1), Nindividuals, 4)
+	cached := newTestPopulation(rand.NewSource(1), Nindividuals, 4)
+	for _, pop := range []*Population[*cfgenome]{&uncached, &cached} {
+		pop.SetElitism(elitism)
+	}
+	cached.SetFitnessCache(true)
+	for gen := 0; gen < Ngen; gen++ {
+		for _, pop := range []*Population[*cfgenome]{&uncached, &cached} {
+			err := pop.Advance(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = pop.Selection(0.2, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		if uncached.ChampionFitness() != cached.ChampionFitness() {
+			t.Fatalf("gen %d: cached and uncached champion fitness differ: %g != %g",
+				gen, uncached.ChampionFitness(), cached.ChampionFitness())
+		}
+	}
+	expectEvals := Nindividuals + (Ngen-1)*(Nindividuals-elitism)
+	if cached.Evaluations() != expectEvals {
+		t.Errorf("expected %d evaluations with cache, got %d", expectEvals, cached.Evaluations())
+	}
+	if uncached.Evaluations() != Nindividuals*Ngen {
+		t.Errorf("expected %d evaluations without cache, got %d", Nindividuals*Ngen, uncached.Evaluations())
+	}
+}
+
+func TestFitnessCacheClones(t *testing.T) {
+	const (
+		Nindividuals = 20
+		Ngen         = 5
+	)
+	ctx := context.Background()
+	pop := newTestPopulation(rand.NewSource(1), Nindividuals, 4)
+	pop.SetElitism(0)
+	pop.SetFitnessCache(true)
+	for gen := 0; gen < Ngen; gen++ {
+		err := pop.Advance(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Without polygamy and with a vanishing mutation rate, since zero is
+		// not a valid rate, every child is an unchanged clone of its parent.
+		err = pop.Selection(math.SmallestNonzeroFloat64, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if pop.Evaluations() != Nindividuals {
+		t.Errorf("clones must not be simulated again: expected %d evaluations, got %d", Nindividuals, pop.Evaluations())
+	}
+}
+
+func TestFitnessCacheHashCollision(t *testing.T) {
+	const Nindividuals = 20
+	ctx := context.Background()
+	src := rand.NewSource(1)
+	individuals := make([]*collidingGenome, Nindividuals)
+	for i := range individuals {
+		individuals[i] = &collidingGenome{newGenome(4)}
+		mu8.Mutate(individuals[i], src, 1)
+	}
+	pop := NewPopulation(individuals, src, func() *collidingGenome { return &collidingGenome{newGenome(4)} })
+	pop.SetFitnessCache(true)
+	for gen := 0; gen < 5; gen++ {
+		err := pop.Advance(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// All hashes collide so fitness is only reused for individuals with equal genes.
+		for i, ind := range pop.Individuals() {
+			if pop.fitness[i] != ind.Simulate(ctx) {
+				t.Fatalf("gen %d: individual %d assigned fitness of colliding individual", gen, i)
+			}
+		}
+		err = pop.Selection(0.5, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestMinimize(t *testing.T) {
+	ctx := context.Background()
+	// Mutate all genes so that no individual starts at the minimum fitness of zero.
+	src := rand.NewSource(1)
+	individuals := make([]*cfgenome, 20)
+	for i := range individuals {
+		individuals[i] = newGenome(4)
+		mu8.Mutate(individuals[i], src, 1)
+	}
+	pop := NewPopulation(individuals, src, func() *cfgenome { return newGenome(4) })
+	pop.SetGoal(Minimize)
+	var first float64
+	for gen := 0; gen < 30; gen++ {
+		err := pop.Advance(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gen == 0 {
+			first = pop.ChampionFitness()
+		}
+		for _, f := range pop.fitness {
+			if f < pop.ChampionFitness() {
+				t.Fatalf("gen %d: individual fitness %g lower than champion %g", gen, f, pop.ChampionFitness())
+			}
+		}
+		err = pop.Selection(0.2, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if pop.ChampionFitness() >= first {
+		t.Errorf("expected champion fitness to decrease from %g, got %g", first, pop.ChampionFitness())
+	}
+}
+
+func TestFitnessTransforms(t *testing.T) {
+	src := []float64{-3, 1, 0.5, 10, -3, 2}
+	transforms := []FitnessTransform{LinearScaling{}, SigmaTruncation{}, Windowing{}, RankScaling{}}
+	for _, transform := range transforms {
+		dst := make([]float64, len(src))
+		transform.Transform(dst, src)
+		for i := range dst {
+			if dst[i] < 0 || math.IsInf(dst[i], 0) || math.IsNaN(dst[i]) {
+				t.Fatalf("%T: bad weight %g", transform, dst[i])
+			}
+			for j := range dst {
+				if src[i] > src[j] && dst[i] < dst[j] {
+					t.Fatalf("%T: order not preserved: %v -> %v", transform, src, dst)
+				}
+			}
+		}
+		equal := []float64{2, 2, 2}
+		transform.Transform(equal, equal)
+		if sumOf(equal) == 0 {
+			t.Errorf("%T: zero weights for equal fitnesses", transform)
+		}
+	}
+}
+
+func TestLinearScaling(t *testing.T) {
+	const multiple = 2
+	for _, src := range [][]float64{
+		{1, 2, 3, 10},  // Fittest scaled to Multiple times the average.
+		{1, 11, 11, 9}, // Fallback: least fit scaled to zero.
+	} {
+		dst := make([]float64, len(src))
+		LinearScaling{Multiple: multiple}.Transform(dst, src)
+		min, max := minmax(src)
+		avg := sumOf(src)/float64(len(src)) - min // Average of windowed fitness.
+		scaledMin, scaledMax := minmax(dst)
+		if got := sumOf(dst) / float64(len(dst)); math.Abs(got-avg) > 1e-12 {
+			t.Errorf("%v: average not preserved: got %g, want %g", src, got, avg)
+		}
+		if math.Abs(scaledMax-multiple*avg) > 1e-12 && scaledMin != 0 {
+			t.Errorf("%v: expected fittest scaled to %g or least fit scaled to zero, got %v", src, multiple*avg, dst)
+		}
+		if fallback := (max-min)-multiple*avg < 0; fallback && scaledMin != 0 {
+			t.Errorf("%v: fallback must scale least fit to zero, got %v", src, dst)
+		}
+	}
+}
+
+func TestSteadyState(t *testing.T) {
+	const (
+		Nindividuals = 20
+		Nsteps       = 50
+		Nchildren    = 2
+	)
+	ctx := context.Background()
+	for _, replacement := range []Replacement{ReplaceWorst, ReplaceLoser, ReplaceCrowding} {
+		pop := newTestPopulation(rand.NewSource(1), Nindividuals, 4)
+		pop.SetReplacement(replacement)
+		err := pop.SteadyState(ctx, 0.2, 1, Nchildren)
+		if err != errNotEvaluated {
+			t.Fatalf("expected errNotEvaluated, got %v", err)
+		}
+		err = pop.Advance(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		best := pop.ChampionFitness()
+		for step := 0; step < Nsteps; step++ {
+			err = pop.SteadyState(ctx, 0.2, 1, Nchildren)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pop.ChampionFitness() < best {
+				t.Fatalf("champion fitness decreased: %g < %g", pop.ChampionFitness(), best)
+			}
+			best = pop.ChampionFitness()
+			for i, ind := range pop.Individuals() {
+				if ind.Simulate(ctx) != pop.fitness[i] {
+					t.Fatalf("step %d: fitness of individual %d out of sync", step, i)
+				}
+			}
+		}
+		if pop.Evaluations() != Nindividuals+Nsteps*Nchildren {
+			t.Errorf("expected %d evaluations, got %d", Nindividuals+Nsteps*Nchildren, pop.Evaluations())
+		}
+	}
+}
+
+var errSolverDiverged = errors.New("solver diverged")
+
+// flakyGenome fails evaluation while its first gene is above 0.5 or
+// during the first `flaky` calls to Evaluate.
+type flakyGenome struct {
+	*cfgenome
+	flaky int
+}
+
+func (g *flakyGenome) Evaluate(ctx context.Context) (float64, error) {
+	if g.flaky > 0 {
+		g.flaky--
+		return 0, errSolverDiverged
+	}
+	if g.genoma[0].Value() > 0.5 {
+		return 0, errSolverDiverged
+	}
+	return g.Simulate(ctx), nil
+}
+
+func TestFailurePolicy(t *testing.T) {
+	const Nindividuals = 20
+	ctx := context.Background()
+	newPop := func(flaky int) Population[*flakyGenome] {
+		src := rand.NewSource(1)
+		individuals := make([]*flakyGenome, Nindividuals)
+		for i := range individuals {
+			individuals[i] = &flakyGenome{cfgenome: newGenome(4), flaky: flaky}
+			mu8.Mutate(individuals[i], src, 1)
+		}
+		return NewPopulation(individuals, src, func() *flakyGenome {
+			return &flakyGenome{cfgenome: newGenome(4)}
+		})
+	}
+
+	pop := newPop(0)
+	pop.SetConcurrency(4)
+	err := pop.Advance(ctx)
+	var evalErr *mu8.EvaluationError
+	if !errors.As(err, &evalErr) || !errors.Is(err, errSolverDiverged) {
+		t.Fatalf("expected evaluation error, got %v", err)
+	}
+	dubious, _ := pop.DubiousIndividual()
+	if evalErr.Individual != dubious {
+		t.Error("evaluation error individual does not match dubious individual")
+	}
+	for i, ind := range pop.Individuals() {
+		if ind == dubious {
+			break
+		} else if ind.genoma[0].Value() > 0.5 {
+			t.Fatalf("individual %d failed before dubious individual", i)
+		}
+	}
+
+	pop = newPop(0)
+	pop.SetFailurePolicy(FailurePolicy{ZeroFitness: true})
+	err = pop.Advance(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, ind := range pop.Individuals() {
+		if ind.genoma[0].Value() > 0.5 && pop.fitness[i] != 0 {
+			t.Fatalf("expected zero fitness for failed individual %d, got %g", i, pop.fitness[i])
+		}
+	}
+
+	pop = newPop(2)
+	pop.SetFailurePolicy(FailurePolicy{Retries: 1})
+	if err = pop.Advance(ctx); !errors.Is(err, errSolverDiverged) {
+		t.Fatalf("expected failure with too few retries, got %v", err)
+	}
+	pop = newPop(2)
+	pop.SetFailurePolicy(FailurePolicy{Retries: 2, ZeroFitness: true})
+	err = pop.Advance(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, ind := range pop.Individuals() {
+		if ind.genoma[0].Value() <= 0.5 && pop.fitness[i] == 0 {
+			t.Fatalf("individual %d did not succeed after retries", i)
+		}
+	}
+
+	// Champion kept by elitism fails once when evaluated again.
+	pop = newPop(0)
+	pop.SetFailurePolicy(FailurePolicy{ZeroFitness: true})
+	err = pop.Advance(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	champFitness := pop.ChampionFitness()
+	err = pop.Selection(0.2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pop.Individuals()[0].flaky = 1 // Elite is placed first.
+	err = pop.Advance(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pop.fitness[0] != 0 || pop.ChampionFitness() != champFitness {
+		t.Errorf("expected failed champion to get zero fitness and remain champion, got %g and %g", pop.fitness[0], pop.ChampionFitness())
+	}
+}
+
+func TestEvaluator(t *testing.T) {
+	ctx := context.Background()
+	pop := newTestPopulation(rand.NewSource(1), 20, 4)
+	// Minimize the sum of genes using a fitness function independent of cfgenome.Simulate.
+	sum := func(_ context.Context, g *cfgenome) (fitness float64) {
+		for i := range g.genoma {
+			fitness -= g.genoma[i].Value()
+		}
+		return fitness
+	}
+	pop.SetEvaluator(sum)
+	pop.SetFitnessTransform(Windowing{})
+	for gen := 0; gen < 30; gen++ {
+		err := pop.Advance(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = pop.Selection(0.2, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := sum(ctx, pop.Champion()); got != pop.ChampionFitness() {
+		t.Fatalf("champion fitness %g does not match evaluator %g", pop.ChampionFitness(), got)
+	}
+	if pop.ChampionFitness() < -0.5 {
+		t.Errorf("expected champion gene sum close to zero, got %g", -pop.ChampionFitness())
+	}
+}
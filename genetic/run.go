@@ -0,0 +1,270 @@
+package genetic
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	errNoStopCriteria = errors.New("no stop criteria: set a RunOptions limit or pass a cancellable context to Run")
+	errBadEpoch       = errors.New("bad epoch: number of generations between migrations must be greater than 1")
+	errBadConcurrency = errors.New("bad concurrency: must be in range [1, Nislands]")
+)
+
+// StopReason describes why Run terminated.
+type StopReason int
+
+const (
+	// StopMaxGenerations means RunOptions.MaxGenerations generations were simulated.
+	StopMaxGenerations StopReason = iota + 1
+	// StopMaxEvaluations means RunOptions.MaxEvaluations simulations were reached or exceeded.
+	StopMaxEvaluations
+	// StopTargetFitness means the champion reached RunOptions.TargetFitness.
+	StopTargetFitness
+	// StopTimeout means the RunOptions.Timeout wall-clock budget was exhausted.
+	StopTimeout
+	// StopStagnation means the champion did not improve during RunOptions.Stagnation generations.
+	StopStagnation
+	// StopCallback means RunOptions.OnGeneration requested the run to stop.
+	StopCallback
+	// StopContext means the context passed to Run was cancelled.
+	StopContext
+	// StopError means the genetic algorithm returned an error.
+	StopError
+)
+
+// String returns a description of the stop reason.
+func (r StopReason) String() string {
+	switch r {
+	case StopMaxGenerations:
+		return "max generations reached"
+	case StopMaxEvaluations:
+		return "max evaluations reached"
+	case StopTargetFitness:
+		return "target fitness reached"
+	case StopTimeout:
+		return "timeout"
+	case StopStagnation:
+		return "stagnation"
+	case StopCallback:
+		return "stopped by callback"
+	case StopContext:
+		return "context cancelled"
+	case StopError:
+		return "error"
+	}
+	return "unknown stop reason"
+}
+
+// RunOptions configures the genetic algorithm loop of Run. The run
+// stops on the first termination criterion met. Zero valued criteria are disabled.
+type RunOptions struct {
+	// MutationRate and Polygamy are passed to Selection or SteadyState.
+	MutationRate float64
+	Polygamy     int
+	// SteadyStateChildren, if greater than zero, makes Run perform steady-state steps
+	// breeding SteadyStateChildren children each instead of generational Selection.
+	// Each steady-state step counts as a generation. Not used by Islands.
+	SteadyStateChildren int
+
+	// MaxGenerations is the maximum number of generations simulated.
+	MaxGenerations int
+	// MaxEvaluations is the maximum number of simulations. Since whole generations
+	// are simulated this number may be exceeded by up to a generation.
+	MaxEvaluations int
+	// TargetFitness, if not nil, stops the run once the champion's fitness is at least as good as it.
+	TargetFitness *float64
+	// Timeout is the wall-clock budget of the run.
+	Timeout time.Duration
+	// Stagnation is the number of consecutive generations without champion
+	// improvement after which the run stops.
+	Stagnation int
+	// OnGeneration, if not nil, is called after each generation is simulated with
+	// its statistics. If it returns true the run stops.
+	OnGeneration func(Stats) (stop bool)
+}
+
+// RunResult describes the outcome of a call to Run.
+type RunResult struct {
+	// Reason is the criterion that stopped the run.
+	Reason StopReason
+	// Generations and Evaluations are the number of generations and
+	// simulations performed during the run.
+	Generations int
+	Evaluations int
+	// ChampionFitness is the fitness of the champion at the end of the run.
+	ChampionFitness float64
+	// Elapsed is the wall-clock duration of the run.
+	Elapsed time.Duration
+}
+
+// runState keeps track of termination criteria during a run.
+type runState struct {
+	opts        RunOptions
+	goal        Goal
+	start       time.Time
+	startEvals  int
+	generations int
+	stagnant    int
+	best        float64
+}
+
+func newRunState(ctx context.Context, opts RunOptions, goal Goal, startEvals int) (runState, error) {
+	noCriteria := opts.MaxGenerations <= 0 && opts.MaxEvaluations <= 0 && opts.TargetFitness == nil &&
+		opts.Timeout <= 0 && opts.Stagnation <= 0 && opts.OnGeneration == nil
+	if noCriteria && ctx.Done() == nil {
+		return runState{}, errNoStopCriteria
+	}
+	return runState{opts: opts, goal: goal, start: time.Now(), startEvals: startEvals}, nil
+}
+
+// check is called after each generation and returns a non-zero StopReason if
+// a termination criterion is met. generations is the number of generations elapsed during the step.
+func (rs *runState) check(stats Stats, generations, evals int, champFitness float64) StopReason {
+	first := rs.generations == 0
+	rs.generations += generations
+	if first || rs.goal.better(champFitness, rs.best) {
+		rs.best = champFitness
+		rs.stagnant = 0
+	} else {
+		rs.stagnant += generations
+	}
+	opts := &rs.opts
+	switch {
+	case opts.OnGeneration != nil && opts.OnGeneration(stats):
+		return StopCallback
+	case opts.TargetFitness != nil && !rs.goal.better(*opts.TargetFitness, champFitness):
+		return StopTargetFitness
+	case opts.MaxGenerations > 0 && rs.generations >= opts.MaxGenerations:
+		return StopMaxGenerations
+	case opts.MaxEvaluations > 0 && evals-rs.startEvals >= opts.MaxEvaluations:
+		return StopMaxEvaluations
+	case opts.Stagnation > 0 && rs.stagnant >= opts.Stagnation:
+		return StopStagnation
+	case opts.Timeout > 0 && time.Since(rs.start) >= opts.Timeout:
+		return StopTimeout
+	}
+	return 0
+}
+
+// result returns the RunResult of the run and the error that stopped it, if any.
+// parent is the context passed to Run and err is the error returned by the genetic algorithm.
+func (rs *runState) result(parent context.Context, reason StopReason, err error, evals int, champFitness float64) (RunResult, error) {
+	if err != nil {
+		switch {
+		case parent.Err() != nil:
+			reason = StopContext
+			err = parent.Err()
+		case errors.Is(err, context.DeadlineExceeded) && rs.opts.Timeout > 0:
+			// Our own timeout expired mid generation.
+			reason = StopTimeout
+			err = nil
+		default:
+			reason = StopError
+		}
+	}
+	return RunResult{
+		Reason:          reason,
+		Generations:     rs.generations,
+		Evaluations:     evals - rs.startEvals,
+		ChampionFitness: champFitness,
+		Elapsed:         time.Since(rs.start),
+	}, err
+}
+
+// withTimeout returns a context that is cancelled after the run's timeout.
+func (rs *runState) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if rs.opts.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, rs.opts.Timeout)
+}
+
+// Run runs the genetic algorithm until one of the termination criteria set in opts is met
+// or ctx is cancelled. Each generation consists of a call to Advance followed by a call to
+// Selection, or of a call to SteadyState if opts.SteadyStateChildren is set. Run returns
+// after simulating the last generation so that the Population's fitness and
+// Stats correspond to its current individuals.
+//
+// An error is returned if the genetic algorithm fails or if ctx is cancelled, in which
+// case the result's Reason is StopError or StopContext, respectively.
+//
+//	result, err := pop.Run(ctx, genetic.RunOptions{
+//		MutationRate:   0.1,
+//		Polygamy:       1,
+//		MaxGenerations: 1000,
+//		Stagnation:     100,
+//	})
+func (pop *Population[G]) Run(ctx context.Context, opts RunOptions) (RunResult, error) {
+	rs, err := newRunState(ctx, opts, pop.goal, pop.evals)
+	if err != nil {
+		return RunResult{}, err
+	}
+	runCtx, cancel := rs.withTimeout(ctx)
+	defer cancel()
+	var reason StopReason
+	for reason == 0 {
+		if opts.SteadyStateChildren > 0 && pop.evaluated {
+			err = pop.SteadyState(runCtx, opts.MutationRate, opts.Polygamy, opts.SteadyStateChildren)
+		} else {
+			err = pop.Advance(runCtx)
+		}
+		if err != nil {
+			break
+		}
+		reason = rs.check(pop.stats, 1, pop.evals, pop.champFitness)
+		if reason == 0 && opts.SteadyStateChildren <= 0 {
+			err = pop.Selection(opts.MutationRate, opts.Polygamy)
+			if err != nil {
+				break
+			}
+		}
+	}
+	return rs.result(ctx, reason, err, pop.evals, pop.champFitness)
+}
+
+// IslandsRunOptions configures the Islands genetic algorithm loop of Run.
+type IslandsRunOptions struct {
+	RunOptions
+	// Epoch is the number of generations simulated on each island between
+	// migrations. Must be greater than 1. Generations count towards MaxGenerations.
+	Epoch int
+	// Concurrency is the number of islands simulated concurrently.
+	// Must be in range [1, Nislands].
+	Concurrency int
+}
+
+// Run runs the Islands Model Genetic Algorithm until one of the termination criteria set in opts
+// is met or ctx is cancelled. Each epoch consists of a call to Advance followed by a call to Crossover
+// and termination criteria are checked between epochs. Generations are counted
+// per island. See [Population.Run].
+func (is *Islands[G]) Run(ctx context.Context, opts IslandsRunOptions) (RunResult, error) {
+	switch {
+	case opts.Epoch <= 1:
+		return RunResult{}, errBadEpoch
+	case opts.Concurrency < 1 || opts.Concurrency > len(is.islands):
+		return RunResult{}, errBadConcurrency
+	}
+	rs, err := newRunState(ctx, opts.RunOptions, is.islands[0].goal, is.evaluations())
+	if err != nil {
+		return RunResult{}, err
+	}
+	runCtx, cancel := rs.withTimeout(ctx)
+	defer cancel()
+	var reason StopReason
+	for reason == 0 {
+		err = is.Advance(runCtx, opts.MutationRate, opts.Polygamy, opts.Epoch, opts.Concurrency)
+		if err != nil {
+			break
+		}
+		reason = rs.check(is.stats, opts.Epoch, is.evaluations(), is.championFitness())
+		if reason == 0 {
+			err = is.Crossover()
+			if err != nil {
+				break
+			}
+		}
+	}
+	return rs.result(ctx, reason, err, is.evaluations(), is.championFitness())
+}
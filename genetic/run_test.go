@@ -0,0 +1,83 @@
+package genetic
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestPopulationRun(t *testing.T) {
+	ctx := context.Background()
+	base := RunOptions{MutationRate: 0.2, Polygamy: 1}
+	for _, test := range []struct {
+		name   string
+		expect StopReason
+		modify func(*RunOptions)
+	}{
+		{"generations", StopMaxGenerations, func(o *RunOptions) { o.MaxGenerations = 10 }},
+		{"evaluations", StopMaxEvaluations, func(o *RunOptions) { o.MaxEvaluations = 100 }},
+		{"target", StopTargetFitness, func(o *RunOptions) {
+			target := 0.01
+			o.TargetFitness = &target
+		}},
+		{"stagnation", StopStagnation, func(o *RunOptions) { o.Stagnation = 5 }},
+		{"timeout", StopTimeout, func(o *RunOptions) { o.Timeout = 10 * time.Millisecond }},
+		{"callback", StopCallback, func(o *RunOptions) {
+			o.OnGeneration = func(s Stats) bool { return s.Generation == 3 }
+		}},
+		{"steadystate", StopMaxGenerations, func(o *RunOptions) { o.MaxGenerations, o.SteadyStateChildren = 10, 2 }},
+	} {
+		pop := newTestPopulation(rand.NewSource(1), 20, 4)
+		opts := base
+		test.modify(&opts)
+		result, err := pop.Run(ctx, opts)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if result.Reason != test.expect {
+			t.Errorf("%s: expected stop reason %q, got %q", test.name, test.expect, result.Reason)
+		}
+		if result.ChampionFitness != pop.ChampionFitness() || result.Evaluations != pop.Evaluations() {
+			t.Errorf("%s: result does not match population: %+v", test.name, result)
+		}
+	}
+	pop := newTestPopulation(rand.NewSource(1), 20, 4)
+	_, err := pop.Run(ctx, base)
+	if err != errNoStopCriteria {
+		t.Errorf("expected errNoStopCriteria, got %v", err)
+	}
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	result, err := pop.Run(cancelled, base)
+	if err != context.Canceled || result.Reason != StopContext {
+		t.Errorf("expected cancelled run, got %v, %v", err, result.Reason)
+	}
+}
+
+func TestIslandsRun(t *testing.T) {
+	individuals := make([]*cfgenome, 50)
+	pop := newTestPopulation(rand.NewSource(1), len(individuals), 4)
+	copy(individuals, pop.Individuals())
+	isls := NewIslands(5, individuals, rand.NewSource(1), func() *cfgenome { return newGenome(4) })
+	result, err := isls.Run(context.Background(), IslandsRunOptions{
+		RunOptions:  RunOptions{MutationRate: 0.2, Polygamy: 1, MaxGenerations: 20},
+		Epoch:       5,
+		Concurrency: 5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Reason != StopMaxGenerations || result.Generations != 20 {
+		t.Errorf("unexpected result %+v", result)
+	}
+	for _, opts := range []IslandsRunOptions{
+		{RunOptions: RunOptions{MaxGenerations: 20}, Epoch: 1, Concurrency: 5},
+		{RunOptions: RunOptions{MaxGenerations: 20}, Epoch: 5, Concurrency: 6},
+	} {
+		_, err = isls.Run(context.Background(), opts)
+		if err == nil {
+			t.Errorf("expected error for bad options %+v", opts)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package genetic
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Selector chooses which individuals of a population become parents during
+// the Selection phase of the genetic algorithm.
+type Selector interface {
+	// Select fills dst with the indices of the individuals chosen for breeding.
+	// fitness contains the non-negative fitness of each individual and is
+	// guaranteed to have a positive sum. Indices may be repeated in dst.
+	// Implementations should not modify or keep a reference to fitness.
+	Select(rng *rand.Rand, dst []int, fitness []float64)
+}
+
+// Compile-time checks of interface implementation.
+var (
+	_ Selector = Roulette{}
+	_ Selector = Tournament{}
+	_ Selector = LinearRank{}
+	_ Selector = StochasticUniversal{}
+	_ Selector = Truncation{}
+)
+
+// Roulette implements fitness-proportional selection. The probability of an individual
+// being selected is its fitness divided by the population's fitness sum.
+// Roulette tends to collapse onto a single individual when it is much fitter
+// than the rest of the population.
+type Roulette struct{}
+
+// Select implements the [Selector] interface.
+func (Roulette) Select(rng *rand.Rand, dst []int, fitness []float64) {
+	sum := sumOf(fitness)
+	for k := range dst {
+		dst[k] = spin(fitness, sum*rng.Float64())
+	}
+}
+
+// Tournament implements tournament selection. Each selected individual is the
+// fittest of Size individuals sampled uniformly from the population.
+// Larger tournaments result in a higher selective pressure.
+type Tournament struct {
+	// Size is the number of contestants per tournament. Sizes below 2 are treated as 2.
+	Size int
+}
+
+// Select implements the [Selector] interface.
+func (t Tournament) Select(rng *rand.Rand, dst []int, fitness []float64) {
+	size := t.Size
+	if size < 2 {
+		size = 2
+	}
+	for k := range dst {
+		winner := rng.Intn(len(fitness))
+		for c := 1; c < size; c++ {
+			contestant := rng.Intn(len(fitness))
+			if fitness[contestant] > fitness[winner] {
+				winner = contestant
+			}
+		}
+		dst[k] = winner
+	}
+}
+
+// LinearRank implements linear ranking selection. The probability of an individual
+// being selected depends only on its rank in the population and not on the
+// magnitude of its fitness, which prevents super-individuals from taking over.
+type LinearRank struct {
+	// Pressure is the expected number of offspring of the fittest individual and
+	// must be in the range [1, 2]. A pressure of 1 results in uniform selection.
+	// Zero value is treated as 1.5.
+	Pressure float64
+}
+
+// Select implements the [Selector] interface.
+func (lr LinearRank) Select(rng *rand.Rand, dst []int, fitness []float64) {
+	s := lr.Pressure
+	if s == 0 {
+		s = 1.5
+	} else if s < 1 || s > 2 {
+		panic("linear rank selection pressure must be in range [1, 2]")
+	}
+	n := float64(len(fitness))
+	ranked := argsort(fitness)
+	if len(ranked) == 1 {
+		for k := range dst {
+			dst[k] = ranked[0]
+		}
+		return
+	}
+	// Rank weights: fittest individual is rank n-1, worst is rank 0.
+	weights := make([]float64, len(ranked))
+	for p := range weights {
+		r := n - 1 - float64(p)
+		weights[p] = (2-s)/n + 2*r*(s-1)/(n*(n-1))
+	}
+	sum := sumOf(weights)
+	for k := range dst {
+		dst[k] = ranked[spin(weights, sum*rng.Float64())]
+	}
+}
+
+// StochasticUniversal implements stochastic universal sampling (SUS). It works
+// like Roulette but uses a single random number to place len(dst) evenly spaced pointers,
+// which guarantees the number of times an individual is selected is close to its expected value.
+type StochasticUniversal struct{}
+
+// Select implements the [Selector] interface.
+func (StochasticUniversal) Select(rng *rand.Rand, dst []int, fitness []float64) {
+	if len(dst) == 0 {
+		return
+	}
+	step := sumOf(fitness) / float64(len(dst))
+	pointer := step * rng.Float64()
+	runningSum := 0.0
+	i := 0
+	for k := range dst {
+		for i < len(fitness)-1 && runningSum+fitness[i] <= pointer {
+			runningSum += fitness[i]
+			i++
+		}
+		dst[k] = i
+		pointer += step
+	}
+}
+
+// Truncation implements truncation selection. Only the fittest fraction of
+// the population is eligible for breeding, each with equal probability.
+type Truncation struct {
+	// Fraction of the population eligible for breeding, in range (0, 1].
+	// Zero value is treated as 0.5.
+	Fraction float64
+}
+
+// Select implements the [Selector] interface.
+func (t Truncation) Select(rng *rand.Rand, dst []int, fitness []float64) {
+	frac := t.Fraction
+	if frac == 0 {
+		frac = 0.5
+	} else if frac < 0 || frac > 1 {
+		panic("truncation fraction must be in range (0, 1]")
+	}
+	ranked := argsort(fitness)
+	eligible := int(math.Ceil(frac * float64(len(ranked))))
+	if eligible < 1 {
+		eligible = 1
+	}
+	fittest := ranked[:eligible]
+	for k := range dst {
+		dst[k] = fittest[rng.Intn(len(fittest))]
+	}
+}
+
+// spin returns the index of the individual at which the running sum of fitness exceeds threshold.
+func spin(fitness []float64, threshold float64) int {
+	runningSum := 0.0
+	last := 0
+	for i, f := range fitness {
+		runningSum += f
+		if runningSum > threshold {
+			return i
+		}
+		if f > 0 {
+			last = i
+		}
+	}
+	// Floating point rounding may leave us here. Return last individual with a chance of being selected.
+	return last
+}
+
+// argsort returns the indices of fitness sorted by descending fitness, fittest first.
+// Sort is stable so that ties are resolved in favor of the lowest index.
+func argsort(fitness []float64) []int {
+	idx := make([]int, len(fitness))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool {
+		return fitness[idx[i]] > fitness[idx[j]]
+	})
+	return idx
+}
+
+func sumOf(s []float64) (sum float64) {
+	for _, v := range s {
+		sum += v
+	}
+	return sum
+}
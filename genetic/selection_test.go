@@ -0,0 +1,88 @@
+package genetic
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+)
+
+func TestSelectors(t *testing.T) {
+	fitness := []float64{0, 1, 2, 0, 10, 3, 0.5}
+	const fittest = 4
+	selectors := map[string]Selector{
+		"roulette":   Roulette{},
+		"tournament": Tournament{Size: 3},
+		"linearrank": LinearRank{Pressure: 2},
+		"sus":        StochasticUniversal{},
+		"truncation": Truncation{Fraction: 0.3},
+	}
+	for name, sel := range selectors {
+		rng := rand.New(rand.NewSource(1))
+		counts := make([]int, len(fitness))
+		dst := make([]int, 5)
+		for i := 0; i < 1000; i++ {
+			sel.Select(rng, dst, fitness)
+			for _, idx := range dst {
+				if idx < 0 || idx >= len(fitness) {
+					t.Fatalf("%s: index %d out of range", name, idx)
+				}
+				counts[idx]++
+			}
+		}
+		// Truncation selects eligible individuals uniformly so we allow some leeway.
+		for i := range counts {
+			if counts[i] > counts[fittest]+counts[fittest]/10 {
+				t.Errorf("%s: individual %d selected more than fittest individual: %v", name, i, counts)
+				break
+			}
+		}
+	}
+}
+
+func TestSelectorZeroFitness(t *testing.T) {
+	fitness := []float64{0, 0, 1, 0}
+	rng := rand.New(rand.NewSource(1))
+	dst := make([]int, 10)
+	for _, sel := range []Selector{Roulette{}, StochasticUniversal{}} {
+		sel.Select(rng, dst, fitness)
+		for _, idx := range dst {
+			if idx != 2 {
+				t.Fatalf("%T selected individual %d with zero fitness", sel, idx)
+			}
+		}
+	}
+}
+
+// countingSelector counts calls to Select and delegates selection to Roulette.
+type countingSelector struct{ calls *int }
+
+func (c countingSelector) Select(rng *rand.Rand, dst []int, fitness []float64) {
+	*c.calls++
+	Roulette{}.Select(rng, dst, fitness)
+}
+
+func TestSelectionWith(t *testing.T) {
+	var setCalls, withCalls int
+	pop := newTestPopulation(rand.NewSource(1), 20, 4)
+	pop.SetSelector(countingSelector{calls: &setCalls})
+	for gen := 0; gen < 4; gen++ {
+		err := pop.Advance(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gen%2 == 0 {
+			err = pop.SelectionWith(countingSelector{calls: &withCalls}, 0.1, 1)
+		} else {
+			err = pop.Selection(0.1, 1)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if setCalls == 0 || withCalls == 0 {
+		t.Errorf("expected both selectors to be used, got %d calls to set selector and %d to per-call selector", setCalls, withCalls)
+	}
+	if _, ok := pop.selector.(countingSelector); !ok || pop.selector.(countingSelector).calls != &setCalls {
+		t.Error("SelectionWith modified the Population's Selector")
+	}
+}
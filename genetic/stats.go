@@ -0,0 +1,82 @@
+package genetic
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Stats summarizes the raw fitness of a population after a call to Advance or SteadyState.
+type Stats struct {
+	// Generation is the generation number of the evaluated population.
+	// It is incremented by every call to Selection and SteadyState.
+	Generation int
+	// Min, Max, Mean, Median and StdDev are statistics of the fitness
+	// of the individuals in the population. StdDev is the population standard deviation.
+	Min, Max, Mean, Median, StdDev float64
+	// Evaluations is the number of simulations performed during the call.
+	Evaluations int
+	// WallTime is the time elapsed during the call.
+	WallTime time.Duration
+	// MutationRate is the mutation rate used to breed the population. Zero for the initial population.
+	MutationRate float64
+	// SuccessRate is the fraction of offspring fitter than their first parent.
+	// Zero if no offspring were simulated.
+	SuccessRate float64
+}
+
+// fitnessStats returns the Stats of fitness with the fitness fields set.
+func fitnessStats(fitness []float64) (s Stats) {
+	if len(fitness) == 0 {
+		return s
+	}
+	sorted := append([]float64(nil), fitness...)
+	sort.Float64s(sorted)
+	s.Min = sorted[0]
+	s.Max = sorted[len(sorted)-1]
+	half := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		s.Median = (sorted[half-1] + sorted[half]) / 2
+	} else {
+		s.Median = sorted[half]
+	}
+	s.Mean, s.StdDev = meanStdDev(fitness)
+	return s
+}
+
+// Stats returns the statistics of the population gathered during
+// the last successful call to Advance or SteadyState.
+func (pop *Population[G]) Stats() Stats { return pop.stats }
+
+// Stats returns statistics aggregated over the individuals of all islands
+// gathered during the last successful call to Advance. Evaluations and WallTime
+// account for all generations simulated during the call and Generation is
+// the highest generation number among islands.
+func (is *Islands[G]) Stats() Stats { return is.stats }
+
+// IslandStats returns the statistics of each island's last generation
+// simulated during the last call to Advance. See [Population.Stats].
+func (is *Islands[G]) IslandStats() []Stats {
+	stats := make([]Stats, len(is.islands))
+	for i := range is.islands {
+		stats[i] = is.islands[i].stats
+	}
+	return stats
+}
+
+// aggregateStats computes the Stats of all island individuals.
+func (is *Islands[G]) aggregateStats(evaluations int, elapsed time.Duration) Stats {
+	var fitness []float64
+	gen := math.MinInt
+	for i := range is.islands {
+		fitness = append(fitness, is.islands[i].fitness...)
+		if is.islands[i].stats.Generation > gen {
+			gen = is.islands[i].stats.Generation
+		}
+	}
+	s := fitnessStats(fitness)
+	s.Generation = gen
+	s.Evaluations = evaluations
+	s.WallTime = elapsed
+	return s
+}
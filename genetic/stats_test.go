@@ -0,0 +1,45 @@
+package genetic
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+)
+
+func TestFitnessStats(t *testing.T) {
+	s := fitnessStats([]float64{4, 1, 3, 2})
+	if s.Min != 1 || s.Max != 4 || s.Mean != 2.5 || s.Median != 2.5 {
+		t.Errorf("bad stats: %+v", s)
+	}
+	s = fitnessStats([]float64{3, 3, 3})
+	if s.StdDev != 0 || s.Median != 3 {
+		t.Errorf("bad stats: %+v", s)
+	}
+}
+
+func TestPopulationStats(t *testing.T) {
+	const Nindividuals = 20
+	ctx := context.Background()
+	pop := newTestPopulation(rand.NewSource(1), Nindividuals, 4)
+	for gen := 0; gen < 5; gen++ {
+		err := pop.Advance(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := pop.Stats()
+		switch {
+		case s.Generation != gen:
+			t.Errorf("expected generation %d, got %d", gen, s.Generation)
+		case s.Evaluations != Nindividuals:
+			t.Errorf("expected %d evaluations, got %d", Nindividuals, s.Evaluations)
+		case s.Max != pop.ChampionFitness():
+			t.Errorf("max fitness %g does not match champion %g", s.Max, pop.ChampionFitness())
+		case s.Min > s.Median || s.Median > s.Max || s.Min > s.Mean || s.Mean > s.Max:
+			t.Errorf("inconsistent stats: %+v", s)
+		}
+		err = pop.Selection(0.2, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package genetic
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+)
+
+var (
+	errNotEvaluated    = errors.New("population not evaluated: call Advance after Selection or after modifying individuals")
+	errBadChildrenSize = errors.New("bad number of children: must be in range [1, Nindividuals-elitism]")
+)
+
+// Replacement is the strategy used to choose which individual of the
+// Population is replaced by a child during a steady-state step.
+type Replacement int
+
+const (
+	// ReplaceWorst replaces the least fit individual. It is the default Replacement.
+	ReplaceWorst Replacement = iota
+	// ReplaceLoser replaces the loser of a binary tournament between two
+	// randomly chosen individuals, which is less greedy than ReplaceWorst.
+	ReplaceLoser
+	// ReplaceCrowding implements deterministic crowding: each child competes with the
+	// most similar of its parents and replaces it only if the child is at least as fit.
+	// Since children replace similar individuals, niches are preserved.
+	// Crowding requires computing the distance between individuals. See [Population.SetDistance].
+	ReplaceCrowding
+)
+
+// SetReplacement sets the strategy used to choose which individuals are
+// replaced by children during SteadyState. The elite as set by SetElitism are never replaced
+// by ReplaceWorst and ReplaceLoser.
+func (pop *Population[G]) SetReplacement(r Replacement) {
+	if r != ReplaceWorst && r != ReplaceLoser && r != ReplaceCrowding {
+		panic("invalid replacement")
+	}
+	pop.replacement = r
+}
+
+// SteadyState performs one step of the steady-state genetic algorithm. Unlike
+// Selection, which replaces the whole population, SteadyState breeds Nchildren children,
+// simulates them and replaces individuals of the population chosen by
+// the Population's Replacement strategy in place. Only the children are simulated.
+//
+// Advance must be called once before the first call to SteadyState so that the fitness of all
+// individuals is known. Calls to Selection invalidate the fitness, requiring a new call to Advance.
+//
+//	err := pop.Advance(ctx)
+//	for i := 0; i < Nsteps && err == nil; i++ {
+//		err = pop.SteadyState(ctx, mutationRate, polygamy, 2)
+//	}
+func (pop *Population[G]) SteadyState(ctx context.Context, mutationRate float64, polygamy, Nchildren int) error {
+	switch {
+	case !pop.evaluated:
+		return errNotEvaluated
+	case pop.fitnessSum == 0:
+		return ErrZeroFitnessSum
+	case mutationRate <= 0 || mutationRate > 1:
+		return errBadMutationRate
+	case polygamy < 0 || polygamy > len(pop.individuals):
+		return errBadPolygamy
+	case pop.elitism >= len(pop.individuals):
+		return errBadElitism
+	case Nchildren <= 0 || Nchildren > len(pop.individuals)-pop.elitism:
+		return errBadChildrenSize
+	case ctx.Err() != nil:
+		return ctx.Err()
+	}
+	start := time.Now()
+	startEvals := pop.evals
+	mutationRate, err := pop.nextMutationRate(mutationRate)
+	if err != nil {
+		return err
+	}
+	seen, err := pop.newGenomeSet(pop.ranking())
+	if err != nil {
+		return err
+	}
+	children := make([]G, Nchildren)
+	parents := make([][]int, Nchildren)
+	parentFitness := make([]float64, Nchildren)
+	for k := range children {
+		child, childParents, _, err := pop.uniqueOffspring(seen, mutationRate, polygamy)
+		if err != nil {
+			return err
+		}
+		children[k] = child
+		parents[k] = childParents
+		parentFitness[k] = pop.fitness[childParents[0]]
+	}
+	fitness := make([]float64, Nchildren)
+	violation := make([]float64, Nchildren) // Stays zero for unconstrained Genomes.
+	var simViolation []float64
+	if pop.violation != nil {
+		simViolation = violation
+	}
+	n, evalErr := pop.simulate(ctx, children, fitness, simViolation, nil)
+	pop.evals += n
+	for k := 0; k < n; k++ {
+		if math.IsInf(fitness[k], 0) || math.IsNaN(fitness[k]) {
+			pop.dubious = fitness[k]
+			pop.dubiousIndividual = children[k]
+			return errInvalidFitness
+		} else if !pop.validFitness(fitness[k]) {
+			pop.dubious = fitness[k]
+			pop.dubiousIndividual = children[k]
+			return errNegativeFitness
+		} else if !validViolation(violation[k]) {
+			pop.dubious = violation[k]
+			pop.dubiousIndividual = children[k]
+			return errInvalidViolation
+		}
+	}
+	if evalErr != nil {
+		return evalErr
+	} else if n < Nchildren {
+		return ctx.Err()
+	}
+
+	improved := 0
+	var replaced []int
+	for k, child := range children {
+		if pop.goal.better(fitness[k], parentFitness[k]) {
+			improved++
+		}
+		var victim int
+		if pop.replacement == ReplaceCrowding {
+			victim, err = pop.closest(child, parents[k])
+			if err != nil {
+				return err
+			}
+			if pop.preferred(pop.fitness[victim], pop.violationOf(victim), fitness[k], violation[k]) {
+				continue // Parent wins, child is discarded.
+			}
+		} else {
+			victim = pop.victim()
+		}
+		pop.individuals[victim] = child
+		pop.fitness[victim] = fitness[k]
+		pop.cached[victim] = pop.useCache
+		pop.cache[victim] = fitness[k]
+		if pop.violation != nil {
+			pop.violation[victim] = violation[k]
+			pop.violationCache[victim] = violation[k]
+		}
+		pop.parentFitness[victim] = math.NaN() // Child's success is accounted for in this step.
+		replaced = append(replaced, victim)
+	}
+	pop.memo = nil
+	fitnessSum, err := pop.computeWeights(true)
+	if err != nil {
+		return err
+	}
+	switch {
+	case fitnessSum == 0:
+		return ErrZeroFitnessSum
+	case math.IsInf(fitnessSum, 0):
+		return ErrInfFitnessSum
+	}
+	pop.fitnessSum = fitnessSum
+	pop.gen++
+	pop.updateStats(start, startEvals, Nchildren, improved)
+	ranked := pop.ranking()
+	err = pop.updateChampions(ranked)
+	if err != nil {
+		return err
+	}
+	// Only children that entered the population may enter the hall of fame.
+	candidates := make([]int, 0, len(replaced))
+	for _, i := range ranked {
+		if contains(replaced, i) {
+			candidates = append(candidates, i)
+		}
+	}
+	return pop.updateHallOfFame(candidates)
+}
+
+// closest returns the index among candidates of the individual closest to child.
+func (pop *Population[G]) closest(child G, candidates []int) (closest int, err error) {
+	minDist := math.Inf(1)
+	for _, idx := range candidates {
+		d, err := pop.genomeDistance(child, pop.individuals[idx])
+		if err != nil {
+			return -1, err
+		}
+		if d < minDist {
+			minDist = d
+			closest = idx
+		}
+	}
+	return closest, nil
+}
+
+// victim returns the index of the individual to be replaced by a child
+// according to the Population's Replacement strategy.
+func (pop *Population[G]) victim() int {
+	ranked := pop.ranking()
+	switch pop.replacement {
+	case ReplaceLoser:
+		// Sample two individuals among the non-elite. The one with the worse rank loses.
+		candidates := len(ranked) - pop.elitism
+		a := pop.rng.Intn(candidates)
+		b := pop.rng.Intn(candidates)
+		if b > a {
+			a = b
+		}
+		return ranked[pop.elitism+a]
+	default:
+		return ranked[len(ranked)-1]
+	}
+}
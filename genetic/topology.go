@@ -0,0 +1,217 @@
+package genetic
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+)
+
+// Topology determines which islands receive the migrants of each island during Crossover.
+type Topology interface {
+	// Destinations returns the indices of the islands that receive the migrants of island src
+	// among n islands. Destinations must be in range [0, n) and should not include src.
+	Destinations(rng *rand.Rand, src, n int) []int
+}
+
+// Compile-time checks of interface implementation.
+var (
+	_ Topology = RandomTopology{}
+	_ Topology = Ring{}
+	_ Topology = BidirectionalRing{}
+	_ Topology = Torus{}
+	_ Topology = FullyConnected{}
+	_ Topology = Star{}
+	_ Topology = Graph{}
+	_ Topology = (*Attractiveness)(nil)
+)
+
+// RandomTopology sends the migrants of each island to another island chosen at random
+// every migration. It is the default Topology.
+type RandomTopology struct{}
+
+// Destinations implements the [Topology] interface.
+func (RandomTopology) Destinations(rng *rand.Rand, src, n int) []int {
+	for {
+		dst := rng.Intn(n)
+		if dst != src {
+			return []int{dst}
+		}
+	}
+}
+
+// Ring sends the migrants of island i to island i+1. The last island sends its migrants
+// to the first one. Migrants spread slowly in a ring which preserves diversity.
+type Ring struct{}
+
+// Destinations implements the [Topology] interface.
+func (Ring) Destinations(_ *rand.Rand, src, n int) []int {
+	return []int{(src + 1) % n}
+}
+
+// BidirectionalRing sends the migrants of island i to islands i-1 and i+1, wrapping around.
+type BidirectionalRing struct{}
+
+// Destinations implements the [Topology] interface.
+func (BidirectionalRing) Destinations(_ *rand.Rand, src, n int) []int {
+	return neighbors(src, (src+n-1)%n, (src+1)%n)
+}
+
+// Torus arranges islands on a two dimensional grid of the given Width whose edges wrap around.
+// The migrants of each island are sent to its four neighbors: up, down, left and right.
+// The number of islands must be a multiple of Width.
+type Torus struct {
+	Width int
+}
+
+// Destinations implements the [Topology] interface.
+func (t Torus) Destinations(_ *rand.Rand, src, n int) []int {
+	w := t.Width
+	if w <= 0 || n%w != 0 {
+		panic("number of islands must be a multiple of torus width")
+	}
+	h := n / w
+	row, col := src/w, src%w
+	return neighbors(src,
+		((row+h-1)%h)*w+col, // Up.
+		((row+1)%h)*w+col,   // Down.
+		row*w+(col+w-1)%w,   // Left.
+		row*w+(col+1)%w,     // Right.
+	)
+}
+
+// FullyConnected sends the migrants of each island to every other island. Migrants spread
+// fast which speeds up convergence at the cost of diversity.
+type FullyConnected struct{}
+
+// Destinations implements the [Topology] interface.
+func (FullyConnected) Destinations(_ *rand.Rand, src, n int) []int {
+	dst := make([]int, 0, n-1)
+	for i := 0; i < n; i++ {
+		if i != src {
+			dst = append(dst, i)
+		}
+	}
+	return dst
+}
+
+// Star sends the migrants of the Center island to all other islands and
+// the migrants of all other islands to the Center island.
+type Star struct {
+	Center int
+}
+
+// Destinations implements the [Topology] interface.
+func (s Star) Destinations(rng *rand.Rand, src, n int) []int {
+	if s.Center < 0 || s.Center >= n {
+		panic("star center out of range of islands")
+	}
+	if src == s.Center {
+		return FullyConnected{}.Destinations(rng, src, n)
+	}
+	return []int{s.Center}
+}
+
+// Graph is a user-defined directed graph of islands. Graph[i] holds
+// the islands that receive the migrants of island i. A Graph must have one
+// adjacency list per island and must not send the migrants of an island to itself,
+// which is checked by [Islands.SetMigration].
+//
+//	// Island 0 feeds islands 1 and 2 which feed back into island 0.
+//	topology := genetic.Graph{{1, 2}, {0}, {0}}
+type Graph [][]int
+
+// Destinations implements the [Topology] interface.
+func (g Graph) Destinations(_ *rand.Rand, src, n int) []int {
+	return g[src]
+}
+
+// validate checks the graph is valid for n islands.
+func (g Graph) validate(n int) error {
+	if len(g) != n {
+		return errBadGraphLen
+	}
+	for src, dsts := range g {
+		for _, dst := range dsts {
+			if dst < 0 || dst >= n {
+				return errBadGraphDest
+			} else if dst == src {
+				return errGraphSelfLoop
+			}
+		}
+	}
+	return nil
+}
+
+// Attractiveness implements dynamic migration: the migrants of each island are sent to another island
+// chosen with probability proportional to its attractiveness. The attractiveness of an island
+// is the mean improvement of the fitness of its individuals during the last epoch and is updated
+// after every epoch so that islands whose populations are improving attract more migrants.
+// Islands that are not improving only receive migrants through exploration.
+//
+// Attractiveness keeps the attractiveness of islands and must not be shared between Islands.
+//
+//	isls.SetMigration(genetic.Migration{Topology: &genetic.Attractiveness{Exploration: 0.2}})
+type Attractiveness struct {
+	// Exploration is the probability of sending migrants to an island chosen uniformly at random
+	// regardless of its attractiveness, in range (0, 1]. Zero value is treated as 0.1.
+	Exploration float64
+
+	mu   sync.Mutex
+	attr []float64
+}
+
+// Destinations implements the [Topology] interface.
+func (a *Attractiveness) Destinations(rng *rand.Rand, src, n int) []int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	exploration := a.Exploration
+	if exploration == 0 {
+		exploration = 0.1
+	}
+	if len(a.attr) != n || rng.Float64() < exploration {
+		return RandomTopology{}.Destinations(rng, src, n)
+	}
+	sum := 0.0
+	for j, attr := range a.attr {
+		if j != src {
+			sum += math.Max(attr, 0)
+		}
+	}
+	if sum == 0 {
+		return RandomTopology{}.Destinations(rng, src, n) // No island is improving.
+	}
+	r := rng.Float64() * sum
+	dst := -1
+	for j, attr := range a.attr {
+		if j == src || attr <= 0 {
+			continue
+		}
+		dst = j
+		r -= attr
+		if r < 0 {
+			break
+		}
+	}
+	return []int{dst}
+}
+
+// update sets the attractiveness of the ith of n islands.
+func (a *Attractiveness) update(i, n int, attr float64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.attr) != n {
+		a.attr = make([]float64, n)
+	}
+	a.attr[i] = attr
+}
+
+// neighbors returns the distinct candidates that are not src.
+func neighbors(src int, candidates ...int) []int {
+	dst := candidates[:0]
+	for _, c := range candidates {
+		if c != src && !contains(dst, c) {
+			dst = append(dst, c)
+		}
+	}
+	return dst
+}
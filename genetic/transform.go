@@ -0,0 +1,186 @@
+package genetic
+
+import (
+	"math"
+)
+
+// Goal is the optimization direction of the genetic algorithm.
+type Goal int
+
+const (
+	// Maximize selects for individuals with higher fitness. It is the default Goal.
+	Maximize Goal = iota
+	// Minimize selects for individuals with lower fitness, i.e. fitness is treated as a cost.
+	Minimize
+)
+
+// String returns the name of the goal.
+func (g Goal) String() string {
+	switch g {
+	case Maximize:
+		return "maximize"
+	case Minimize:
+		return "minimize"
+	}
+	return "unknown goal"
+}
+
+// better reports whether fitness a is better than fitness b under the goal.
+func (g Goal) better(a, b float64) bool {
+	if g == Minimize {
+		return a < b
+	}
+	return a > b
+}
+
+// FitnessTransform maps fitnesses to non-negative selection weights. Transforms are
+// applied after Advance and before Selection. Selectors and migration decisions
+// see transformed fitnesses while the Champion, elite and ChampionFitness are
+// determined by the raw fitness returned by Simulate.
+type FitnessTransform interface {
+	// Transform stores the selection weight of each fitness of src in dst.
+	// Higher values in src are better regardless of the Goal: the fitness of Populations
+	// that minimize is negated before being passed to Transform.
+	// Weights stored in dst must be non-negative and finite and should
+	// preserve the order of src.
+	Transform(dst, src []float64)
+}
+
+// Compile-time checks of interface implementation.
+var (
+	_ FitnessTransform = LinearScaling{}
+	_ FitnessTransform = SigmaTruncation{}
+	_ FitnessTransform = Windowing{}
+	_ FitnessTransform = RankScaling{}
+)
+
+// LinearScaling implements Goldberg's linear fitness scaling. Fitness is first windowed so that
+// the least fit individual has zero fitness and then scaled linearly so that the
+// average is preserved and the fittest individual's weight is Multiple times the average.
+// If the scaling would result in negative weights Goldberg's fallback is used: the least fit
+// individual is scaled to zero while preserving the average, which reduces Multiple. Since windowed
+// fitness already has a minimum of zero, the fallback weights are the windowed fitness.
+type LinearScaling struct {
+	// Multiple is the expected number of copies of the fittest individual.
+	// Usually in range [1.2, 2]. Zero value is treated as 2.
+	Multiple float64
+}
+
+// Transform implements the [FitnessTransform] interface.
+func (ls LinearScaling) Transform(dst, src []float64) {
+	c := ls.Multiple
+	if c == 0 {
+		c = 2
+	}
+	min, max := minmax(src)
+	if min == max {
+		fill(dst, 1)
+		return
+	}
+	avg := 0.0
+	for i := range src {
+		dst[i] = src[i] - min
+		avg += dst[i]
+	}
+	max -= min
+	avg /= float64(len(src))
+	a := (c - 1) * avg / (max - avg)
+	b := avg * (max - c*avg) / (max - avg)
+	if b < 0 {
+		// Fallback scaling pins the minimum to zero and preserves the average. Minimum of
+		// windowed fitness is zero so a=1 and b=0: weights are left windowed.
+		return
+	}
+	for i := range dst {
+		dst[i] = a*dst[i] + b
+	}
+}
+
+// SigmaTruncation subtracts the mean fitness minus C standard deviations from each fitness
+// and truncates the result to zero. Selective pressure is thus independent of the
+// fitness offset and scale.
+type SigmaTruncation struct {
+	// C is the number of standard deviations below the mean at which
+	// fitness is truncated to zero. Usually in range [1, 3]. Zero value is treated as 2.
+	C float64
+}
+
+// Transform implements the [FitnessTransform] interface.
+func (st SigmaTruncation) Transform(dst, src []float64) {
+	c := st.C
+	if c == 0 {
+		c = 2
+	}
+	mean, stddev := meanStdDev(src)
+	if stddev == 0 {
+		fill(dst, 1)
+		return
+	}
+	offset := mean - c*stddev
+	for i := range src {
+		dst[i] = math.Max(0, src[i]-offset)
+	}
+}
+
+// Windowing subtracts the fitness of the least fit individual from each fitness.
+// The least fit individual is thus never selected. It is the default
+// transform of Populations that minimize.
+type Windowing struct{}
+
+// Transform implements the [FitnessTransform] interface.
+func (Windowing) Transform(dst, src []float64) {
+	min, max := minmax(src)
+	if min == max {
+		fill(dst, 1)
+		return
+	}
+	for i := range src {
+		dst[i] = src[i] - min
+	}
+}
+
+// RankScaling replaces each fitness with its rank in the population: the least fit
+// individual gets a weight of 1 and the fittest a weight of len(src). Individuals with
+// equal fitness share the same rank.
+type RankScaling struct{}
+
+// Transform implements the [FitnessTransform] interface.
+func (RankScaling) Transform(dst, src []float64) {
+	ranked := argsort(src)
+	rank := float64(len(src))
+	for k, i := range ranked {
+		if k > 0 && src[i] != src[ranked[k-1]] {
+			rank = float64(len(src) - k)
+		}
+		dst[i] = rank
+	}
+}
+
+func minmax(s []float64) (min, max float64) {
+	min, max = math.Inf(1), math.Inf(-1)
+	for _, v := range s {
+		min = math.Min(min, v)
+		max = math.Max(max, v)
+	}
+	return min, max
+}
+
+func meanStdDev(s []float64) (mean, stddev float64) {
+	if len(s) == 0 {
+		return 0, 0
+	}
+	for _, v := range s {
+		mean += v
+	}
+	mean /= float64(len(s))
+	for _, v := range s {
+		stddev += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(stddev / float64(len(s)))
+}
+
+func fill(s []float64, v float64) {
+	for i := range s {
+		s[i] = v
+	}
+}
@@ -3,6 +3,7 @@ package mu8
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math"
 	"math/rand"
 )
@@ -21,14 +22,52 @@ type Genome interface {
 	// values into the simulation
 	Simulate(context.Context) (fitness float64)
 
-	// GetGene gets ith gene in the Genome. It is expected the ith Genes
-	// of two Genomes in a Genetic Algorithm instance have matching types.
+	Genotype
+}
+
+// Genotype is the genetic makeup of a candidate: an ordered set of Genes.
+// Genotype is embedded in Genome and MultiGenome.
+type Genotype interface {
+	// GetGene gets ith gene in the Genotype. It is expected the ith Genes
+	// of two Genotypes in a Genetic Algorithm instance have matching types.
 	GetGene(i int) Gene
 
-	// Number of Genes in Genome.
+	// Number of Genes in Genotype.
 	Len() int
 }
 
+// MultiGenome represents a candidate for multi-objective genetic algorithm selection,
+// where several competing objectives are optimized at once.
+type MultiGenome interface {
+	// Simulate runs the backing simulation and returns a number quantifying how
+	// well the MultiGenome did for each objective. The number of objectives
+	// returned must be the same for all MultiGenomes of a Genetic Algorithm instance.
+	// See Genome's Simulate for the use of the context.
+	Simulate(context.Context) (objectives []float64)
+
+	Genotype
+}
+
+// Evaluator is implemented by Genomes whose simulation can fail, such as simulations
+// that run external solvers. Genetic algorithm implementations and Gradient call
+// Evaluate instead of Simulate when it is available.
+type Evaluator interface {
+	// Evaluate runs the backing simulation and returns the fitness of the Genome
+	// as Simulate would, or an error if the simulation failed. See Genome's Simulate.
+	Evaluate(context.Context) (fitness float64, err error)
+}
+
+// Constrained is implemented by Genomes subject to constraints that span several Genes,
+// such as a mass budget or a geometric clearance, which can't be expressed as per-Gene bounds.
+// Individuals that violate constraints are said to be infeasible.
+type Constrained interface {
+	// Violation returns the total constraint violation of the Genome as found during
+	// the last call to Simulate. It must be zero for feasible Genomes and positive for infeasible
+	// Genomes, usually the sum of the amounts by which each constraint is violated.
+	// Violation is called right after Simulate or Evaluate.
+	Violation() float64
+}
+
 // Gene is the basic physical and functional unit of heredity.
 type Gene interface {
 	// Splice modifies the receiver with the attributes of the argument. It should NOT
@@ -45,9 +84,10 @@ type Gene interface {
 	Mutate(rng *rand.Rand)
 }
 
-// Mutate mutates the Genes in the Genome g, modifying g in place.
+// Mutate mutates the Genes in the Genotype g, modifying g in place.
 // The probability of a Gene being mutated is mutationRate/1.
-func Mutate(g Genome, src rand.Source, mutationRate float64) {
+// It returns the number of Genes that were mutated.
+func Mutate(g Genotype, src rand.Source, mutationRate float64) (mutated int) {
 	switch {
 	case mutationRate == 0:
 		panic("can't mutate with zero mutation rate")
@@ -60,13 +100,15 @@ func Mutate(g Genome, src rand.Source, mutationRate float64) {
 		r := rng.Float64()
 		if r < mutationRate {
 			g.GetGene(i).Mutate(rng)
+			mutated++
 		}
 	}
+	return mutated
 }
 
 // Clone clones the Genes of src to dst. It does not
 // modify src. dst should be initialized beforehand.
-func Clone(dst, src Genome) error {
+func Clone(dst, src Genotype) error {
 	if dst == nil {
 		return errors.New("got nil destination for Clone")
 	} else if src == nil {
@@ -81,6 +123,90 @@ func Clone(dst, src Genome) error {
 	return nil
 }
 
+// GeneDistancer is implemented by Genes that can quantify how different they
+// are from another Gene of the same type. Distances are used to preserve
+// diversity in the genetic algorithm.
+type GeneDistancer interface {
+	Gene
+	// Distance returns a non-negative measure of the difference between the receiver and
+	// the argument which is zero for identical Genes. It is recommended distances be normalized so that
+	// Genes with different ranges have comparable distances. It should NOT modify the argument.
+	Distance(g Gene) float64
+}
+
+// Distance returns the sum of the distances between the Genes of a and b. All Genes must
+// implement the GeneDistancer interface. It does not modify a nor b.
+func Distance(a, b Genotype) (float64, error) {
+	if a == nil || b == nil {
+		return 0, errors.New("got nil Genotype for Distance")
+	} else if a.Len() != b.Len() {
+		return 0, errors.New("genome length mismatch")
+	}
+	sum := 0.0
+	for i := 0; i < a.Len(); i++ {
+		gene, ok := a.GetGene(i).(GeneDistancer)
+		if !ok {
+			return 0, fmt.Errorf("gene %d of type %T does not implement GeneDistancer", i, a.GetGene(i))
+		}
+		sum += gene.Distance(b.GetGene(i))
+	}
+	return sum, nil
+}
+
+// GeneHasher is implemented by Genes that can report whether they are equal to
+// another Gene of the same type and a hash of their genetic content. Equality
+// allows genetic algorithms to detect duplicate individuals.
+type GeneHasher interface {
+	Gene
+	// Equal reports whether the receiver and the argument have identical genetic content.
+	// It should NOT modify the argument.
+	Equal(g Gene) bool
+	// Hash returns a hash of the genetic content of the Gene. Equal Genes must
+	// return the same hash.
+	Hash() uint64
+}
+
+// Hash returns a hash of the Genes of g which depends on the order of the Genes.
+// All Genes must implement the GeneHasher interface. Genotypes with equal
+// Genes have the same hash.
+func Hash(g Genotype) (uint64, error) {
+	if g == nil {
+		return 0, errors.New("got nil Genotype for Hash")
+	}
+	// FNV-1a offset basis and prime.
+	const prime = 1099511628211
+	h := uint64(14695981039346656037)
+	for i := 0; i < g.Len(); i++ {
+		gene, ok := g.GetGene(i).(GeneHasher)
+		if !ok {
+			return 0, fmt.Errorf("gene %d of type %T does not implement GeneHasher", i, g.GetGene(i))
+		}
+		h ^= gene.Hash()
+		h *= prime
+	}
+	return h, nil
+}
+
+// Equal reports whether all Genes of a and b are equal. All Genes must implement
+// the GeneHasher interface. It does not modify a nor b.
+func Equal(a, b Genotype) (bool, error) {
+	if a == nil || b == nil {
+		return false, errors.New("got nil Genotype for Equal")
+	} else if a.Len() != b.Len() {
+		return false, errors.New("genome length mismatch")
+	}
+	for i := 0; i < a.Len(); i++ {
+		gene, ok := a.GetGene(i).(GeneHasher)
+		if !ok {
+			return false, fmt.Errorf("gene %d of type %T does not implement GeneHasher", i, a.GetGene(i))
+		}
+		if !gene.Equal(b.GetGene(i)) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
 // GenomeGrad is a Genome that can be used with gradient descent.
 type GenomeGrad interface {
 	Simulate(context.Context) (fitness float64)
@@ -97,7 +223,8 @@ type GeneGrad interface {
 
 // Gradient computes the gradient of the GenomeGrad g using finite differences.
 // It stores the result of the calculation to grad. The length of grad must match
-// the number of Genes in g.
+// the number of Genes in g. If g implements Evaluator, failed evaluations
+// are returned as an *EvaluationError.
 //
 // # Use of newIndividual argument
 //
@@ -108,7 +235,10 @@ func Gradient[T GenomeGrad](ctx context.Context, grad []float64, startIndividual
 	if startIndividual.LenGrad() != len(grad) {
 		panic("scratch length mismatch")
 	}
-	startFitness := startIndividual.Simulate(ctx)
+	startFitness, err := evaluate(ctx, startIndividual)
+	if err != nil {
+		return err
+	}
 	for i := 0; i < startIndividual.LenGrad() && ctx.Err() == nil; i++ {
 		if newIndividual != nil {
 			blankSlate := newIndividual()
@@ -122,7 +252,10 @@ func Gradient[T GenomeGrad](ctx context.Context, grad []float64, startIndividual
 			return errors.New("zero step size")
 		}
 		gene.SetValue(start + step)
-		newFitness := startIndividual.Simulate(ctx)
+		newFitness, err := evaluate(ctx, startIndividual)
+		if err != nil {
+			return err
+		}
 		if newFitness < 0 {
 			return ErrNegativeFitness
 		} else if math.IsNaN(newFitness) || math.IsInf(newFitness, 0) {
@@ -134,6 +267,19 @@ func Gradient[T GenomeGrad](ctx context.Context, grad []float64, startIndividual
 	return ctx.Err()
 }
 
+// evaluate simulates g, calling Evaluate if g implements Evaluator.
+func evaluate(ctx context.Context, g GenomeGrad) (float64, error) {
+	evaluator, ok := g.(Evaluator)
+	if !ok {
+		return g.Simulate(ctx), nil
+	}
+	fitness, err := evaluator.Evaluate(ctx)
+	if err != nil {
+		return 0, &EvaluationError{Individual: g, Err: err}
+	}
+	return fitness, nil
+}
+
 // CloneGrad clones all the genes of src to dst. It does not modify src.
 func CloneGrad(dst, src GenomeGrad) error {
 	if dst == nil {
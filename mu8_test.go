@@ -50,15 +50,15 @@ func ExampleGenome_population() {
 	}
 	// Output:
 	// champ fitness=0.081
-	// champ fitness=0.832
-	// champ fitness=0.860
-	// champ fitness=0.887
-	// champ fitness=0.887
-	// champ fitness=0.926
-	// champ fitness=0.926
-	// champ fitness=0.926
-	// champ fitness=0.926
-	// champ fitness=0.953
+	// champ fitness=0.858
+	// champ fitness=0.936
+	// champ fitness=0.956
+	// champ fitness=0.956
+	// champ fitness=0.960
+	// champ fitness=0.960
+	// champ fitness=0.960
+	// champ fitness=0.971
+	// champ fitness=0.983
 }
 
 type mygenome struct {
@@ -109,21 +109,24 @@ func ExampleGenome_islands() {
 		if err != nil {
 			panic(err.Error())
 		}
-		isls.Crossover()
+		err = isls.Crossover()
+		if err != nil {
+			panic(err.Error())
+		}
 		champFitness := isls.ChampionFitness()
 		fmt.Printf("champ fitness=%.3f\n", champFitness)
 	}
 	// Output:
-	// champ fitness=0.882
-	// champ fitness=0.897
-	// champ fitness=0.923
-	// champ fitness=0.946
-	// champ fitness=0.946
-	// champ fitness=0.946
-	// champ fitness=0.946
-	// champ fitness=0.946
-	// champ fitness=0.956
-	// champ fitness=0.956
+	// champ fitness=0.810
+	// champ fitness=0.842
+	// champ fitness=0.852
+	// champ fitness=0.884
+	// champ fitness=0.906
+	// champ fitness=0.950
+	// champ fitness=0.950
+	// champ fitness=0.963
+	// champ fitness=0.963
+	// champ fitness=0.963
 }
 
 func ExampleGradient() {
@@ -0,0 +1,124 @@
+package mu8
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/bits"
+	"math/rand"
+)
+
+// SplitSource is a rand.Source that can be split into statistically independent streams
+// of random numbers, i.e: one per island or per worker.
+type SplitSource interface {
+	rand.Source
+	// Split returns a new source whose stream does not overlap with the receiver's.
+	Split() rand.Source
+}
+
+// Compile-time checks of interface implementation.
+var (
+	_ rand.Source64 = (*Source)(nil)
+	_ SplitSource   = (*Source)(nil)
+)
+
+// Source is a small and fast xoshiro256** pseudo random number generator. Unlike
+// the sources of math/rand its state can be saved with MarshalBinary and restored
+// with UnmarshalBinary, which allows checkpointing genetic algorithm runs, and it can be split
+// into independent streams with Split. Source is not safe for concurrent use.
+//
+//	pop := genetic.NewPopulation(individuals, mu8.NewSource(1), newIndividual)
+type Source struct {
+	s [4]uint64
+}
+
+// NewSource returns a Source seeded with seed.
+func NewSource(seed int64) *Source {
+	var src Source
+	src.Seed(seed)
+	return &src
+}
+
+// Seed initializes the state of the Source with seed using the splitmix64 generator
+// as recommended by the xoshiro authors. Seed implements the [rand.Source] interface.
+func (src *Source) Seed(seed int64) {
+	x := uint64(seed)
+	for i := range src.s {
+		x += 0x9e3779b97f4a7c15
+		z := x
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		src.s[i] = z ^ (z >> 31)
+	}
+}
+
+// Uint64 returns a pseudo-random 64-bit value. Uint64 implements the [rand.Source64] interface.
+func (src *Source) Uint64() uint64 {
+	s := &src.s
+	result := bits.RotateLeft64(s[1]*5, 7) * 9
+	t := s[1] << 17
+	s[2] ^= s[0]
+	s[3] ^= s[1]
+	s[1] ^= s[2]
+	s[0] ^= s[3]
+	s[2] ^= t
+	s[3] = bits.RotateLeft64(s[3], 45)
+	return result
+}
+
+// Int63 returns a non-negative pseudo-random 63-bit integer. Int63 implements the [rand.Source] interface.
+func (src *Source) Int63() int64 {
+	return int64(src.Uint64() >> 1)
+}
+
+// Split returns a new Source starting at the receiver's current state and
+// advances the receiver by 2^128 steps. Sources returned by successive calls to Split
+// thus produce non-overlapping streams of 2^128 numbers. Split implements the [SplitSource] interface.
+func (src *Source) Split() rand.Source {
+	split := *src
+	src.jump()
+	return &split
+}
+
+// jump advances the state by 2^128 steps.
+func (src *Source) jump() {
+	jump := [4]uint64{0x180ec6d33cfd0aba, 0xd5a61266f0c9392c, 0xa9582618e03fc9aa, 0x39abdc4529b1661c}
+	var s [4]uint64
+	for _, j := range jump {
+		for b := 0; b < 64; b++ {
+			if j&(1<<b) != 0 {
+				s[0] ^= src.s[0]
+				s[1] ^= src.s[1]
+				s[2] ^= src.s[2]
+				s[3] ^= src.s[3]
+			}
+			src.Uint64()
+		}
+	}
+	src.s = s
+}
+
+// MarshalBinary returns the state of the Source. It implements the [encoding.BinaryMarshaler] interface.
+func (src *Source) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 8*len(src.s))
+	for i, v := range src.s {
+		binary.LittleEndian.PutUint64(b[8*i:], v)
+	}
+	return b, nil
+}
+
+// UnmarshalBinary restores the state of the Source saved by MarshalBinary.
+// It implements the [encoding.BinaryUnmarshaler] interface.
+func (src *Source) UnmarshalBinary(data []byte) error {
+	if len(data) != 8*len(src.s) {
+		return errors.New("invalid Source state length")
+	}
+	var s [4]uint64
+	for i := range s {
+		s[i] = binary.LittleEndian.Uint64(data[8*i:])
+	}
+	if s == [4]uint64{} {
+		return errors.New("invalid all-zero Source state")
+	}
+	src.s = s
+	return nil
+}
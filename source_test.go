@@ -0,0 +1,50 @@
+package mu8_test
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/soypat/mu8"
+)
+
+func TestSource(t *testing.T) {
+	// Reference output of xoshiro256** with state {1, 2, 3, 4}.
+	state := make([]byte, 32)
+	for i := range [4]int{} {
+		binary.LittleEndian.PutUint64(state[8*i:], uint64(i+1))
+	}
+	var src mu8.Source
+	err := src.UnmarshalBinary(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expect := range []uint64{11520, 0, 1509978240, 1215971899390074240} {
+		got := src.Uint64()
+		if got != expect {
+			t.Fatalf("got %d, expected %d", got, expect)
+		}
+	}
+
+	// Saved state resumes the same stream.
+	saved, _ := src.MarshalBinary()
+	want := src.Uint64()
+	var resumed mu8.Source
+	err = resumed.UnmarshalBinary(saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resumed.Uint64(); got != want {
+		t.Fatalf("resumed source returned %d, expected %d", got, want)
+	}
+
+	// Split streams differ from each other and from parent.
+	parent := mu8.NewSource(1)
+	a := parent.Split()
+	b := parent.Split()
+	if a.Int63() == b.Int63() || b.Int63() == parent.Int63() {
+		t.Fatal("split sources returned identical numbers")
+	}
+	if err := src.UnmarshalBinary(make([]byte, 32)); err == nil {
+		t.Error("expected error for all-zero state")
+	}
+}